@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type keyAlias struct {
+	oldKey string
+	newKey string
+}
+
+// DeprecatedKey describes deprecated property that is still used in config file
+// or environment variables.
+type DeprecatedKey struct {
+	// OldKey is the deprecated key.
+	OldKey string
+	// NewKey is the key replacing the deprecated one.
+	NewKey string
+	// Message is the explanation provided on registration.
+	Message string
+	// Source describes where the deprecated key was found, e.g. 'file ./config.yaml'
+	// or 'env DB_HOST'.
+	Source string
+}
+
+// RegisterAlias makes lookups via either of the keys resolve to the same property.
+// The new key takes precedence if both keys are set. Aliases also apply to the
+// nested properties of sections, e.g. after RegisterAlias("db", "database")
+// property 'db.host' resolves to 'database.host' and vice versa.
+func (c *Config) RegisterAlias(oldKey, newKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.aliases = append(c.aliases, keyAlias{oldKey: c.normalizeKey(oldKey), newKey: c.normalizeKey(newKey)})
+}
+
+// RegisterDeprecated registers the old key as an alias of the new key and checks
+// whether the old key is set in config file or environment variable. If so, the
+// warning with the message is logged once and the key is included into the
+// report returned by DeprecatedKeysInUse. The check is repeated on every reload,
+// so the old key appearing in the reloaded config is reported as well.
+func (c *Config) RegisterDeprecated(oldKey, newKey, message string) {
+	c.RegisterAlias(oldKey, newKey)
+	deprecated := DeprecatedKey{OldKey: c.normalizeKey(oldKey), NewKey: c.normalizeKey(newKey), Message: message}
+	c.mu.Lock()
+	c.registered = append(c.registered, deprecated)
+	c.mu.Unlock()
+	c.checkDeprecated(deprecated)
+}
+
+// checkDeprecations checks every registered deprecated key, see RegisterDeprecated.
+func (c *Config) checkDeprecations() {
+	c.mu.RLock()
+	registered := append([]DeprecatedKey(nil), c.registered...)
+	c.mu.RUnlock()
+	for _, deprecated := range registered {
+		c.checkDeprecated(deprecated)
+	}
+}
+
+// checkDeprecated logs the warning if the deprecated key is set and wasn't
+// reported before.
+func (c *Config) checkDeprecated(deprecated DeprecatedKey) {
+	if _, found := lookupProp(deprecated.OldKey, c.props()); found {
+		deprecated.Source = "file " + c.fileSource(deprecated.OldKey).Name
+	} else if _, found := c.lookupEnv(envVarName(deprecated.OldKey)); found {
+		deprecated.Source = "env " + envVarName(deprecated.OldKey)
+	} else {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, warned := c.deprecations[deprecated.OldKey]; warned {
+		return
+	}
+	if c.deprecations == nil {
+		c.deprecations = make(map[string]*DeprecatedKey)
+	}
+	c.deprecations[deprecated.OldKey] = &deprecated
+	c.logger.Printf("Warning: %s", deprecated)
+}
+
+// DeprecatedKeysInUse returns deprecated keys found in config file or environment
+// variables, sorted by the old key.
+func (c *Config) DeprecatedKeysInUse() []DeprecatedKey {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	report := make([]DeprecatedKey, 0, len(c.deprecations))
+	for _, deprecated := range c.deprecations {
+		report = append(report, *deprecated)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].OldKey < report[j].OldKey
+	})
+	return report
+}
+
+// String formats the deprecation in human-readable form.
+func (d DeprecatedKey) String() string {
+	msg := fmt.Sprintf("property %s found in %s is deprecated, use %s instead", d.OldKey, d.Source, d.NewKey)
+	if d.Message != "" {
+		msg += ": " + d.Message
+	}
+	return msg
+}
+
+// keyCandidates returns the normalized key followed by its aliases in order of precedence.
+func (c *Config) keyCandidates(key string) []string {
+	key = c.normalizeKey(key)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.aliases) == 0 {
+		return []string{key}
+	}
+	candidates := []string{key}
+	for _, alias := range c.aliases {
+		if replaced, ok := replaceKeyPrefix(key, alias.oldKey, alias.newKey); ok {
+			candidates = append([]string{replaced}, candidates...)
+		} else if replaced, ok := replaceKeyPrefix(key, alias.newKey, alias.oldKey); ok {
+			candidates = append(candidates, replaced)
+		}
+	}
+	return candidates
+}
+
+// replaceKeyPrefix replaces the section prefix of the key if the key equals
+// to the prefix or is nested into it.
+func replaceKeyPrefix(key, prefix, replacement string) (string, bool) {
+	if key == prefix {
+		return replacement, true
+	}
+	if strings.HasPrefix(key, prefix+".") || strings.HasPrefix(key, prefix+"[") {
+		return replacement + key[len(prefix):], true
+	}
+	return "", false
+}
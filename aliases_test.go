@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	assertions "github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestConfig_RegisterAlias(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_deprecated.yaml", Yaml)
+	config.RegisterAlias("db.host", "database.host")
+	config.RegisterAlias("cache", "caching")
+
+	assert.Equal("db.example.com", config.GetString("database.host"))
+	assert.Equal("db.example.com", config.GetString("db.host"))
+	assert.Equal(60, config.GetInt("caching.ttl"))
+	assert.True(config.IsSet("caching.ttl"))
+	assert.False(config.IsSet("caching.size"))
+
+	config.RegisterAlias("db.port", "database.port")
+	assert.Equal(5433, config.GetInt("db.port"))
+	assert.Equal(5433, config.GetInt("database.port"))
+}
+
+func TestConfig_RegisterAliasEnvs(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_deprecated.yaml", Yaml)
+	config.RegisterAlias("db.user", "database.user")
+
+	err := os.Setenv("DB_USER", "old_user")
+	assert.Nil(err)
+	assert.Equal("old_user", config.GetString("database.user"))
+	err = os.Setenv("DATABASE_USER", "new_user")
+	assert.Nil(err)
+	assert.Equal("new_user", config.GetString("db.user"))
+
+	err = os.Unsetenv("DB_USER")
+	assert.Nil(err)
+	err = os.Unsetenv("DATABASE_USER")
+	assert.Nil(err)
+}
+
+func TestConfig_RegisterDeprecated(t *testing.T) {
+	assert := assertions.New(t)
+
+	logger := &testLogger{}
+	config := NewConfig("./test_config_deprecated.yaml", Yaml, WithLogger(logger))
+
+	err := os.Setenv("CACHE_SIZE", "10")
+	assert.Nil(err)
+
+	config.RegisterDeprecated("db.host", "database.host", "will be removed in v2")
+	config.RegisterDeprecated("db.host", "database.host", "will be removed in v2")
+	config.RegisterDeprecated("cache.size", "caching.size", "")
+	config.RegisterDeprecated("db.user", "database.user", "")
+
+	assert.Equal("db.example.com", config.GetString("database.host"))
+	assert.Equal(10, config.GetInt("caching.size"))
+	assert.Equal([]string{
+		"Warning: property db.host found in file ./test_config_deprecated.yaml is deprecated, use database.host instead: will be removed in v2",
+		"Warning: property cache.size found in env CACHE_SIZE is deprecated, use caching.size instead",
+	}, logger.messages)
+	assert.Equal([]DeprecatedKey{
+		{OldKey: "cache.size", NewKey: "caching.size", Source: "env CACHE_SIZE"},
+		{OldKey: "db.host", NewKey: "database.host", Message: "will be removed in v2",
+			Source: "file ./test_config_deprecated.yaml"},
+	}, config.DeprecatedKeysInUse())
+
+	err = os.Unsetenv("CACHE_SIZE")
+	assert.Nil(err)
+}
+
+func TestConfig_RegisterDeprecatedReload(t *testing.T) {
+	assert := assertions.New(t)
+
+	path := writeTempFile(t, "config.yaml", "database:\n  host: new.example.com\n")
+	logger := &testLogger{}
+	config := NewConfig(path, Yaml, WithLogger(logger))
+	config.RegisterDeprecated("db.host", "database.host", "")
+	assert.Empty(config.DeprecatedKeysInUse())
+
+	assert.NoError(ioutil.WriteFile(path, []byte("db:\n  host: old.example.com\n"), 0644))
+	assert.NoError(config.Reload())
+	assert.Equal([]DeprecatedKey{
+		{OldKey: "db.host", NewKey: "database.host", Source: "file " + path},
+	}, config.DeprecatedKeysInUse())
+	assert.Len(logger.messages, 1)
+}
+
+func TestWithLogger_Nil(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_deprecated.yaml", Yaml, WithLogger(nil))
+	assert.NotPanics(func() {
+		config.RegisterDeprecated("db.host", "database.host", "")
+	})
+	assert.Len(config.DeprecatedKeysInUse(), 1)
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// KeyConflict describes a fully qualified key that is reachable in more than one
+// way, e.g. through literal dotted key 'key2.subkey1' and through nested section
+// 'key2' containing 'subkey1'. Lookup of such key returns only one of the values.
+type KeyConflict struct {
+	// Key is the fully qualified dotted key.
+	Key string
+	// Locations lists every place in the properties tree where the key is declared.
+	Locations []KeyLocation
+}
+
+// KeyLocation is a single declaration of the property in the properties tree.
+type KeyLocation struct {
+	// Pointer is JSON pointer to the declaration, e.g. '/root/family1/key2.subkey1'.
+	Pointer string
+	// Value is the value declared at this location.
+	Value interface{}
+}
+
+// String formats the conflict in human-readable form.
+func (k KeyConflict) String() string {
+	locations := make([]string, 0, len(k.Locations))
+	for _, loc := range k.Locations {
+		locations = append(locations, fmt.Sprintf("%s = %v", loc.Pointer, loc.Value))
+	}
+	return fmt.Sprintf("key %s is declared ambiguously: %s", k.Key, strings.Join(locations, ", "))
+}
+
+// AmbiguousKeys returns every key path reachable in more than one way.
+// Sections declared both in nested and literal dotted form are not reported
+// as long as they do not collide with a property value.
+// Conflicts are sorted by key.
+func (c *Config) AmbiguousKeys() []KeyConflict {
+	locations := make(map[string][]KeyLocation)
+	walkNodes("", "", c.props(), func(key, pointer string, val interface{}) {
+		locations[key] = append(locations[key], KeyLocation{Pointer: pointer, Value: val})
+	})
+
+	var conflicts []KeyConflict
+	for key, locs := range locations {
+		if len(locs) < 2 || allSections(locs) {
+			continue
+		}
+		sort.Slice(locs, func(i, j int) bool {
+			return locs[i].Pointer < locs[j].Pointer
+		})
+		conflicts = append(conflicts, KeyConflict{Key: key, Locations: locs})
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Key < conflicts[j].Key
+	})
+	return conflicts
+}
+
+func (c *Config) checkAmbiguousKeys() {
+	if c.ambiguityCheck == AmbiguityIgnore {
+		return
+	}
+	conflicts := c.AmbiguousKeys()
+	if len(conflicts) == 0 {
+		return
+	}
+	messages := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		messages = append(messages, conflict.String())
+	}
+	if c.ambiguityCheck == AmbiguityError {
+		log.Panicf("Config file contains ambiguous keys: %s", strings.Join(messages, "; "))
+	}
+	for _, msg := range messages {
+		c.logger.Printf("Warning: %s", msg)
+	}
+}
+
+// walkNodes calls fn for each node of the map, both sections and leaf properties,
+// with its fully qualified key and JSON pointer.
+func walkNodes(prefix, pointer string, props map[string]interface{}, fn func(key, pointer string, val interface{})) {
+	for key, val := range props {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+		fullPointer := pointer + "/" + escapeJSONPointer(key)
+		fn(fullKey, fullPointer, val)
+		if submap, ok := val.(map[string]interface{}); ok {
+			walkNodes(fullKey, fullPointer, submap, fn)
+		}
+	}
+}
+
+func allSections(locs []KeyLocation) bool {
+	for _, loc := range locs {
+		if _, ok := loc.Value.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func escapeJSONPointer(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
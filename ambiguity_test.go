@@ -0,0 +1,49 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfig_AmbiguousKeys(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_ambiguous.yaml", Yaml, WithAmbiguityCheck(AmbiguityIgnore))
+
+	conflicts := config.AmbiguousKeys()
+	assert.Equal([]KeyConflict{
+		{
+			Key: "root.key2.subkey1",
+			Locations: []KeyLocation{
+				{Pointer: "/root/key2.subkey1", Value: "literal"},
+				{Pointer: "/root/key2/subkey1", Value: "nested"},
+			},
+		},
+	}, conflicts)
+	assert.Equal("key root.key2.subkey1 is declared ambiguously: /root/key2.subkey1 = literal, /root/key2/subkey1 = nested",
+		conflicts[0].String())
+}
+
+func TestConfig_NoAmbiguousKeys(t *testing.T) {
+	assert := assertions.New(t)
+
+	assert.Empty(NewConfig("./test_config.yaml", Yaml, WithAmbiguityCheck(AmbiguityError)).AmbiguousKeys())
+	assert.Empty(NewConfig("./test_config.json", Json, WithAmbiguityCheck(AmbiguityError)).AmbiguousKeys())
+}
+
+func TestConfig_AmbiguityWarn(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_ambiguous.yaml", Yaml)
+	assert.Equal("a", config.GetString("section.nested.key1"))
+	assert.Equal("b", config.GetString("section.nested.key2"))
+}
+
+func TestConfig_AmbiguityError(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on ambiguous keys")
+		}
+	}()
+	_ = NewConfig("./test_config_ambiguous.yaml", Yaml, WithAmbiguityCheck(AmbiguityError))
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Bind reads properties of the section specified by key into the struct pointed
+// by target. Empty key binds top-level properties.
+//
+// Property key of the field is taken from the 'config' tag, e.g. `config:"host"`,
+// or is the field name with the first letter in lower case. Fields tagged with
+// `config:"-"` and unexported fields are skipped. Properties are resolved the same
+// way as by getters: from config file, then from env variable, then from the
+// 'default' tag, e.g. `default:"8080"`. Missing properties leave fields unchanged.
+//
+// Supported field types are strings, bools, ints, uints, floats, time.Duration,
+// nested structs and slices of them. Slices are read from lists of config file,
+// env variable value is split by commas. Fields are validated according to the
+// 'validate' tag after binding, see Validator for the list of rules.
+//
+// Bind returns ValidationError listing all fields that couldn't be bound or are invalid.
+func (c *Config) Bind(key string, target interface{}) error {
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a non-nil pointer to struct, got %T", target)
+	}
+	var errs ValidationError
+	c.bindStruct(key, val.Elem(), &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (c *Config) bindStruct(prefix string, structVal reflect.Value, errs *ValidationError) {
+	structType := structVal.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := fieldKey(field)
+		if name == "" {
+			continue
+		}
+		key := joinKey(prefix, name)
+		fieldVal := structVal.Field(i)
+		if fieldVal.Kind() == reflect.Struct {
+			c.bindStruct(key, fieldVal, errs)
+			continue
+		}
+		if c.bindField(key, field, fieldVal, errs) {
+			validateField(key, field, fieldVal, errs)
+		}
+	}
+	validateStruct(prefix, structVal, errs)
+}
+
+// bindField resolves the property and sets it to the field. It returns false if
+// the property couldn't be converted to the field type.
+func (c *Config) bindField(key string, field reflect.StructField, fieldVal reflect.Value, errs *ValidationError) bool {
+	raw := c.GetProp(key)
+	if raw == nil {
+		if env := c.readStringFromEnv(key); env != "" {
+			raw = env
+		} else if defaultVal, ok := field.Tag.Lookup("default"); ok {
+			raw = defaultVal
+		} else {
+			return true
+		}
+	}
+	if err := c.setValue(key, fieldVal, raw, errs); err != nil {
+		*errs = append(*errs, KeyError{Key: key, EnvVar: envVarName(key), Value: raw, Message: err.Error()})
+		return false
+	}
+	return true
+}
+
+func (c *Config) setValue(key string, fieldVal reflect.Value, raw interface{}, errs *ValidationError) error {
+	strVal, isString := raw.(string)
+	if fieldVal.Type() == durationType {
+		if !isString {
+			return fmt.Errorf("value is not convertible to duration")
+		}
+		duration, err := time.ParseDuration(strVal)
+		if err != nil {
+			return fmt.Errorf("value is not convertible to duration")
+		}
+		fieldVal.SetInt(int64(duration))
+		return nil
+	}
+
+	switch fieldVal.Kind() {
+	case reflect.String:
+		fieldVal.SetString(fmt.Sprintf("%v", raw))
+	case reflect.Bool:
+		if boolVal, ok := raw.(bool); ok {
+			fieldVal.SetBool(boolVal)
+		} else if boolVal, err := strconv.ParseBool(strVal); isString && err == nil {
+			fieldVal.SetBool(boolVal)
+		} else {
+			return fmt.Errorf("value is not convertible to bool")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intVal, err := parseInt(raw, strVal, isString)
+		if err != nil || fieldVal.OverflowInt(intVal) {
+			return fmt.Errorf("value is not convertible to %s", fieldVal.Type())
+		}
+		fieldVal.SetInt(intVal)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		intVal, err := parseInt(raw, strVal, isString)
+		if err != nil || intVal < 0 || fieldVal.OverflowUint(uint64(intVal)) {
+			return fmt.Errorf("value is not convertible to %s", fieldVal.Type())
+		}
+		fieldVal.SetUint(uint64(intVal))
+	case reflect.Float32, reflect.Float64:
+		floatVal, ok := raw.(float64)
+		if isString {
+			var err error
+			if floatVal, err = strconv.ParseFloat(strVal, fieldVal.Type().Bits()); err == nil {
+				ok = true
+			}
+		}
+		if !ok {
+			return fmt.Errorf("value is not convertible to %s", fieldVal.Type())
+		}
+		fieldVal.SetFloat(floatVal)
+	case reflect.Slice:
+		return c.setSlice(key, fieldVal, raw, errs)
+	default:
+		return fmt.Errorf("unsupported field type %s", fieldVal.Type())
+	}
+	return nil
+}
+
+func (c *Config) setSlice(key string, fieldVal reflect.Value, raw interface{}, errs *ValidationError) error {
+	var elems []interface{}
+	switch typed := raw.(type) {
+	case []interface{}:
+		elems = typed
+	case string:
+		for _, elem := range strings.Split(typed, ",") {
+			elems = append(elems, strings.TrimSpace(elem))
+		}
+	default:
+		return fmt.Errorf("value is not convertible to %s", fieldVal.Type())
+	}
+	slice := reflect.MakeSlice(fieldVal.Type(), len(elems), len(elems))
+	for i, elem := range elems {
+		elemKey := fmt.Sprintf("%s[%d]", key, i)
+		if slice.Index(i).Kind() == reflect.Struct {
+			c.bindStruct(elemKey, slice.Index(i), errs)
+			continue
+		}
+		if err := c.setValue(elemKey, slice.Index(i), elem, errs); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	fieldVal.Set(slice)
+	return nil
+}
+
+func parseInt(raw interface{}, strVal string, isString bool) (int64, error) {
+	if isString {
+		return strconv.ParseInt(strVal, 10, 64)
+	}
+	if floatVal, ok := raw.(float64); ok && floatVal == float64(int64(floatVal)) {
+		return int64(floatVal), nil
+	}
+	return 0, fmt.Errorf("value is not an integer")
+}
+
+// fieldKey returns property key of the struct field or empty string if the field is skipped.
+func fieldKey(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("config"); ok {
+		if tag == "-" {
+			return ""
+		}
+		return tag
+	}
+	runes := []rune(field.Name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
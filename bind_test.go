@@ -0,0 +1,157 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+type testDbConfig struct {
+	Host string
+	Port int
+	User string `default:"admin"`
+}
+
+type testServerConfig struct {
+	Host string
+	Port uint16
+}
+
+type testAppConfig struct {
+	Name       string
+	Debug      bool
+	Workers    int8
+	Ratio      float32
+	Timeout    time.Duration
+	Tags       []string
+	Db         testDbConfig
+	Servers    []testServerConfig
+	CustomName string `config:"custom_name"`
+	Region     string `config:"region" default:"eu"`
+	Ignored    string `config:"-"`
+	Missing    int
+	unexported string
+}
+
+func TestConfig_Bind(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_bind.yaml", Yaml)
+	app := testAppConfig{Ignored: "keep", Missing: 7}
+	assert.Nil(config.Bind("app", &app))
+
+	assert.Equal(testAppConfig{
+		Name:       "shop",
+		Debug:      true,
+		Workers:    4,
+		Ratio:      0.75,
+		Timeout:    1500 * time.Millisecond,
+		Tags:       []string{"a", "b"},
+		Db:         testDbConfig{Host: "db.example.com", Port: 5432, User: "admin"},
+		Servers:    []testServerConfig{{Host: "alpha.example.com", Port: 8080}, {Host: "beta.example.com", Port: 8081}},
+		CustomName: "custom",
+		Region:     "eu",
+		Ignored:    "keep",
+		Missing:    7,
+	}, app)
+}
+
+func TestConfig_BindEnvs(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_bind.yaml", Yaml)
+
+	err := os.Setenv("APP_REGION", "us")
+	assert.Nil(err)
+	err = os.Setenv("APP_DB_USER", "root")
+	assert.Nil(err)
+	err = os.Setenv("APP_MISSING", "9")
+	assert.Nil(err)
+	err = os.Setenv("APP_SERVERS_1_HOST", "ignored.example.com")
+	assert.Nil(err)
+
+	app := testAppConfig{}
+	assert.Nil(config.Bind("app", &app))
+	assert.Equal("us", app.Region)
+	assert.Equal("root", app.Db.User)
+	assert.Equal(9, app.Missing)
+	assert.Equal("beta.example.com", app.Servers[1].Host)
+
+	err = os.Setenv("TEST_BIND_TAGS", "x, y")
+	assert.Nil(err)
+	var tagsHolder struct {
+		Tags []string
+	}
+	assert.Nil(config.Bind("test.bind", &tagsHolder))
+	assert.Equal([]string{"x", "y"}, tagsHolder.Tags)
+
+	err = os.Unsetenv("APP_REGION")
+	assert.Nil(err)
+	err = os.Unsetenv("APP_DB_USER")
+	assert.Nil(err)
+	err = os.Unsetenv("APP_MISSING")
+	assert.Nil(err)
+	err = os.Unsetenv("APP_SERVERS_1_HOST")
+	assert.Nil(err)
+	err = os.Unsetenv("TEST_BIND_TAGS")
+	assert.Nil(err)
+}
+
+func TestConfig_BindCaseInsensitive(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_case.yaml", Yaml, WithCaseInsensitiveKeys())
+	var family struct {
+		Key1 string
+	}
+	assert.Nil(config.Bind("Root.Family1", &family))
+	assert.Equal("test11", family.Key1)
+}
+
+func TestConfig_BindConversionErrors(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_bind.yaml", Yaml)
+	var invalid struct {
+		Name    bool
+		Workers uint `config:"workers_typed"`
+		Ratio   int8
+		Port    int8
+		Tags    []int
+		Host    map[string]string
+		Code    time.Duration
+	}
+	err := config.Bind("invalid", &invalid)
+	assert.Equal(ValidationError{
+		{Key: "invalid.name", EnvVar: "INVALID_NAME", Value: "", Message: "value is not convertible to bool"},
+		{Key: "invalid.workers_typed", EnvVar: "INVALID_WORKERS_TYPED", Value: "many", Message: "value is not convertible to uint"},
+		{Key: "invalid.ratio", EnvVar: "INVALID_RATIO", Value: 1.5, Message: "value is not convertible to int8"},
+		{Key: "invalid.port", EnvVar: "INVALID_PORT", Value: float64(70000), Message: "value is not convertible to int8"},
+		{Key: "invalid.tags", EnvVar: "INVALID_TAGS", Value: []interface{}{"a", "b", "c"},
+			Message: "element 0: value is not convertible to int"},
+		{Key: "invalid.host", EnvVar: "INVALID_HOST", Value: "-bad-host-", Message: "unsupported field type map[string]string"},
+		{Key: "invalid.code", EnvVar: "INVALID_CODE", Value: "AB-12", Message: "value is not convertible to duration"},
+	}, err)
+}
+
+func TestConfig_BindInvalidTarget(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_bind.yaml", Yaml)
+	app := testAppConfig{}
+	assert.NotNil(config.Bind("app", app))
+	assert.NotNil(config.Bind("app", nil))
+	name := ""
+	assert.NotNil(config.Bind("app", &name))
+}
+
+func TestConfig_BindMarksKeysKnown(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_bind.yaml", Yaml)
+	config.DeclareKnownKeys("invalid")
+	app := testAppConfig{}
+	assert.Nil(config.Bind("app", &app))
+	assert.Empty(config.UnknownKeys())
+}
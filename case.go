@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// WithCaseInsensitiveKeys makes property lookup case-insensitive.
+// All keys of the properties tree are converted to lower case on load, so
+// 'Root.Family1' and 'root.family1' resolve to the same property, and keys
+// returned by AllKeys and Keys are in lower case.
+// NewConfig panics if some keys of the same section differ only in case.
+func WithCaseInsensitiveKeys() Option {
+	return func(c *Config) {
+		c.caseInsensitive = true
+	}
+}
+
+// normalizeKey converts the key according to the case sensitivity of the config.
+func (c *Config) normalizeKey(key string) string {
+	if c.caseInsensitive {
+		return strings.ToLower(key)
+	}
+	return key
+}
+
+func (c *Config) normalizeProperties() {
+	if !c.caseInsensitive {
+		return
+	}
+	var collisions []string
+	c.properties = lowerCaseKeys("", c.properties, &collisions).(map[string]interface{})
+	if len(collisions) > 0 {
+		sort.Strings(collisions)
+		log.Panicf("Config file contains keys colliding in case-insensitive mode: %s",
+			strings.Join(collisions, "; "))
+	}
+}
+
+// lowerCaseKeys returns copy of the value with all map keys converted to lower case.
+// Keys of the same map that differ only in case are appended to collisions.
+func lowerCaseKeys(prefix string, val interface{}, collisions *[]string) interface{} {
+	switch typed := val.(type) {
+	case map[string]interface{}:
+		originals := make(map[string][]string)
+		result := make(map[string]interface{}, len(typed))
+		for key, nested := range typed {
+			lowerKey := strings.ToLower(key)
+			originals[lowerKey] = append(originals[lowerKey], key)
+			result[lowerKey] = lowerCaseKeys(joinKey(prefix, lowerKey), nested, collisions)
+		}
+		for lowerKey, keys := range originals {
+			if len(keys) > 1 {
+				sort.Strings(keys)
+				*collisions = append(*collisions,
+					fmt.Sprintf("%s: %s", joinKey(prefix, lowerKey), strings.Join(keys, ", ")))
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, elem := range typed {
+			result[i] = lowerCaseKeys(fmt.Sprintf("%s[%d]", prefix, i), elem, collisions)
+		}
+		return result
+	default:
+		return val
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfig_CaseInsensitiveKeys(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_case.yaml", Yaml, WithCaseInsensitiveKeys())
+
+	assert.Equal("test11", config.GetString("root.family1.key1"))
+	assert.Equal("test11", config.GetString("Root.Family1.Key1"))
+	assert.Equal("test11", config.RequireString("ROOT.FAMILY1.KEY1"))
+	assert.Equal("test121", config.GetString("root.family1.key2.subkey1"))
+	assert.Equal("alpha", config.GetString("Root.Servers[0].HOST"))
+	assert.True(config.IsSet("ROOT.family1"))
+	assert.Equal([]string{"root.family1.key1", "root.family1.key2.subkey1", "root.servers"}, config.AllKeys())
+	assert.Equal([]string{"family1", "servers"}, config.Keys("ROOT"))
+}
+
+func TestConfig_CaseSensitiveKeys(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_case.yaml", Yaml)
+
+	assert.Equal("test11", config.GetString("Root.Family1.Key1"))
+	assert.Nil(config.GetProp("root.family1.key1"))
+	assert.Equal([]string{"Family1", "servers"}, config.Keys("Root"))
+}
+
+func TestConfig_CaseInsensitiveKeysCollision(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Errorf("Expected panic on colliding keys")
+		}
+		assert := assertions.New(t)
+		assert.Contains(r, "root: Root, root")
+		assert.Contains(r, "root.key1: Key1, key1")
+	}()
+	_ = NewConfig("./test_config_case_collision.yaml", Yaml, WithCaseInsensitiveKeys())
+}
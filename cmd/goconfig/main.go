@@ -0,0 +1,269 @@
+// Command goconfig reads, converts, validates and compares config files the
+// same way the go-config library does.
+//
+// Usage:
+//
+//	goconfig get [-default value] <file> <key>
+//	goconfig convert [-to format] <file>
+//	goconfig validate -schema <schema> <file>
+//	goconfig flatten <file>
+//	goconfig env <file>
+//	goconfig diff [-json] <file1> <file2>
+//	goconfig envdoc [-format markdown|dotenv] <file>
+//
+// File format is detected by extension. Properties missing in file are read
+// from environment variables, see config.EnvVarName.
+//
+// Commands exit with code 2 on usage errors and 1 on other errors; get fails
+// if the property is not set and no default is given. Like diff utility, diff
+// exits with 1 if the files differ and with 2 on errors.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+
+	config "github.com/iglin/go-config"
+)
+
+const usage = `Usage: goconfig <command> [arguments]
+
+Commands:
+  get [-default value] <file> <key>  print property resolved like GetString
+  convert [-to format] <file>        convert file to another format (yaml, json, toml, properties)
+  validate -schema <schema> <file>   validate file against JSON schema
+  flatten <file>                     print all properties with dotted keys
+  env <file>                         print env variable names of all properties
+  diff [-json] <file1> <file2>       print differences between two files
+  envdoc [-format markdown|dotenv] <file>
+                                     document env variables as Markdown table or sample .env file
+`
+
+// command runs the subcommand with its arguments and returns exit code.
+type command func(args []string, stdout, stderr io.Writer) int
+
+var commands = map[string]command{
+	"get":      runGet,
+	"convert":  runConvert,
+	"validate": runValidate,
+	"flatten":  runFlatten,
+	"env":      runEnv,
+	"diff":     runDiff,
+	"envdoc":   runEnvdoc,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) (code int) {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "goconfig: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	// the library panics on load errors after logging them, the message is
+	// printed once on recover instead
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(stderr, "goconfig: %v\n", r)
+			code = 1
+		}
+	}()
+	return cmd(args[1:], stdout, stderr)
+}
+
+// newFlagSet creates FlagSet of the subcommand printing errors to stderr.
+func newFlagSet(name, arguments string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: goconfig %s %s\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses flags and checks the number of positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string, positional int) bool {
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if fs.NArg() != positional {
+		fs.Usage()
+		return false
+	}
+	return true
+}
+
+func loadConfig(path string, stderr io.Writer) *config.Config {
+	return config.NewConfig(path, config.Auto, config.WithLogger(log.New(stderr, "goconfig: ", 0)))
+}
+
+func runGet(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("get", "[-default value] <file> <key>", stderr)
+	defaultVal := fs.String("default", "", "value printed if property is missing")
+	if !parseArgs(fs, args, 2) {
+		return 2
+	}
+	hasDefault := false
+	fs.Visit(func(f *flag.Flag) {
+		hasDefault = hasDefault || f.Name == "default"
+	})
+	cfg := loadConfig(fs.Arg(0), stderr)
+	key := fs.Arg(1)
+	if !hasDefault && !cfg.IsSet(key) {
+		fmt.Fprintf(stderr, "goconfig: property %s is not set and env variable %s is empty\n", key, config.EnvVarName(key))
+		return 1
+	}
+	fmt.Fprintln(stdout, cfg.GetString(key, *defaultVal))
+	return 0
+}
+
+func runConvert(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("convert", "[-to format] <file>", stderr)
+	to := fs.String("to", "", "output format, json for YAML files and yaml for others by default")
+	if !parseArgs(fs, args, 1) {
+		return 2
+	}
+	cfg := loadConfig(fs.Arg(0), stderr)
+	target := *to
+	if target == "" {
+		target = "yaml"
+		if from, ok := config.FormatByExtension(fs.Arg(0)); ok && from == config.Yaml {
+			target = "json"
+		}
+	}
+	format, ok := config.FormatByName(target)
+	if !ok {
+		fmt.Fprintf(stderr, "goconfig: unknown format %q\n", target)
+		return 2
+	}
+	data, err := cfg.Marshal(format)
+	if err != nil {
+		fmt.Fprintf(stderr, "goconfig: %v\n", err)
+		return 1
+	}
+	_, _ = stdout.Write(data)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		fmt.Fprintln(stdout)
+	}
+	return 0
+}
+
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("validate", "-schema <schema> <file>", stderr)
+	schemaPath := fs.String("schema", "", "JSON schema file in JSON or YAML format")
+	if !parseArgs(fs, args, 1) {
+		return 2
+	}
+	if *schemaPath == "" {
+		fs.Usage()
+		return 2
+	}
+	schema, err := config.LoadSchema(*schemaPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "goconfig: %v\n", err)
+		return 1
+	}
+	cfg := loadConfig(fs.Arg(0), stderr)
+	if err := cfg.ValidateSchema(schema); err != nil {
+		for _, violation := range err.(config.SchemaError) {
+			fmt.Fprintln(stdout, violation)
+		}
+		return 1
+	}
+	fmt.Fprintln(stdout, "ok")
+	return 0
+}
+
+func runFlatten(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("flatten", "<file>", stderr)
+	if !parseArgs(fs, args, 1) {
+		return 2
+	}
+	cfg := loadConfig(fs.Arg(0), stderr)
+	for _, key := range cfg.AllKeys() {
+		fmt.Fprintf(stdout, "%s=%s\n", key, config.FormatValue(cfg.GetProp(key)))
+	}
+	return 0
+}
+
+func runEnv(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("env", "<file>", stderr)
+	if !parseArgs(fs, args, 1) {
+		return 2
+	}
+	cfg := loadConfig(fs.Arg(0), stderr)
+	keys := cfg.AllKeys()
+	width := 0
+	for _, key := range keys {
+		if len(config.EnvVarName(key)) > width {
+			width = len(config.EnvVarName(key))
+		}
+	}
+	for _, key := range keys {
+		fmt.Fprintf(stdout, "%-*s %s\n", width, config.EnvVarName(key), key)
+	}
+	return 0
+}
+
+func runDiff(args []string, stdout, stderr io.Writer) (code int) {
+	fs := newFlagSet("diff", "[-json] <file1> <file2>", stderr)
+	asJSON := fs.Bool("json", false, "print diff as JSON")
+	if !parseArgs(fs, args, 2) {
+		return 2
+	}
+	// exit code 1 is reserved for differences, errors exit with 2 like diff utility does
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(stderr, "goconfig: %v\n", r)
+			code = 2
+		}
+	}()
+	diff := config.Diff(loadConfig(fs.Arg(0), stderr), loadConfig(fs.Arg(1), stderr))
+	if *asJSON {
+		data, err := diff.JSON()
+		if err != nil {
+			fmt.Fprintf(stderr, "goconfig: %v\n", err)
+			return 2
+		}
+		fmt.Fprintln(stdout, string(data))
+	} else {
+		fmt.Fprint(stdout, diff.Unified())
+	}
+	// exit code 1 means differences are found, like diff utility does
+	if diff.Empty() {
+		return 0
+	}
+	return 1
+}
+
+func runEnvdoc(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("envdoc", "[-format markdown|dotenv] <file>", stderr)
+	format := fs.String("format", "markdown", "output format: markdown or dotenv")
+	if !parseArgs(fs, args, 1) {
+		return 2
+	}
+	vars := loadConfig(fs.Arg(0), stderr).EnvVars()
+	switch *format {
+	case "markdown":
+		fmt.Fprint(stdout, config.EnvVarsMarkdown(vars))
+	case "dotenv":
+		fmt.Fprint(stdout, config.EnvVarsTemplate(vars))
+	default:
+		fmt.Fprintf(stderr, "goconfig: unknown format %q\n", *format)
+		return 2
+	}
+	return 0
+}
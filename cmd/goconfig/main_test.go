@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	assertions "github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestGet(t *testing.T) {
+	assert := assertions.New(t)
+	assert.NoError(os.Setenv("CLI_ONLY_ENV", "from-env"))
+	defer os.Unsetenv("CLI_ONLY_ENV")
+
+	code, stdout, _ := runCommand("get", "../../test_config.yaml", "root.family1.key1")
+	assert.Equal(0, code)
+	assert.Equal("test11\n", stdout)
+
+	_, stdout, _ = runCommand("get", "../../test_config.yaml", "cli.only.env")
+	assert.Equal("from-env\n", stdout)
+
+	_, stdout, _ = runCommand("get", "-default", "fallback", "../../test_config.yaml", "missing.key")
+	assert.Equal("fallback\n", stdout)
+
+	_, stdout, _ = runCommand("get", "-default", "", "../../test_config.yaml", "missing.key")
+	assert.Equal("\n", stdout)
+
+	code, stdout, stderr := runCommand("get", "../../test_config.yaml", "missing.key")
+	assert.Equal(1, code)
+	assert.Empty(stdout)
+	assert.Contains(stderr, "goconfig: property missing.key is not set and env variable MISSING_KEY is empty")
+
+	code, _, stderr = runCommand("get", "../../missing.yaml", "key")
+	assert.Equal(1, code)
+	assert.Contains(stderr, "goconfig: Failed to read json config file")
+
+	code, _, stderr = runCommand("get", "../../test_config.yaml")
+	assert.Equal(2, code)
+	assert.Contains(stderr, "Usage: goconfig get")
+}
+
+func TestConvert(t *testing.T) {
+	assert := assertions.New(t)
+
+	code, stdout, _ := runCommand("convert", "../../test_config.yaml")
+	assert.Equal(0, code)
+	assert.True(strings.HasPrefix(stdout, "{\n"))
+	assert.Contains(stdout, `"key1": "test11"`)
+
+	code, stdout, _ = runCommand("convert", "../../test_config.json")
+	assert.Equal(0, code)
+	assert.Contains(stdout, "key1: test11")
+
+	code, _, stderr := runCommand("convert", "-to", "xml", "../../test_config.json")
+	assert.Equal(2, code)
+	assert.Contains(stderr, `unknown format "xml"`)
+}
+
+func TestValidate(t *testing.T) {
+	assert := assertions.New(t)
+
+	code, stdout, _ := runCommand("validate", "-schema", "../../test_schema.yaml", "../../test_config.yaml")
+	assert.Equal(0, code)
+	assert.Equal("ok\n", stdout)
+
+	schemaPath := filepath.Join(t.TempDir(), "schema.json")
+	assert.NoError(ioutil.WriteFile(schemaPath, []byte(`{"required": ["missing"]}`), 0644))
+	code, stdout, _ = runCommand("validate", "-schema", schemaPath, "../../test_config.yaml")
+	assert.Equal(1, code)
+	assert.Equal("/missing: required property is missing\n", stdout)
+
+	code, _, _ = runCommand("validate", "../../test_config.yaml")
+	assert.Equal(2, code)
+}
+
+func TestFlattenAndEnv(t *testing.T) {
+	assert := assertions.New(t)
+
+	code, stdout, _ := runCommand("flatten", "../../test_config.yaml")
+	assert.Equal(0, code)
+	assert.Contains(stdout, "root.family1.key1=test11\n")
+	assert.Contains(stdout, "root.family3.key1=true\n")
+
+	code, stdout, _ = runCommand("env", "../../test_config.yaml")
+	assert.Equal(0, code)
+	assert.Contains(stdout, "ROOT_FAMILY1_KEY1")
+	assert.Contains(stdout, " root.family1.key1\n")
+}
+
+func TestDiff(t *testing.T) {
+	assert := assertions.New(t)
+
+	code, stdout, _ := runCommand("diff", "../../test_config.yaml", "../../test_config.json")
+	assert.Equal(0, code)
+	assert.Equal("--- ../../test_config.yaml\n+++ ../../test_config.json\n", stdout)
+
+	code, stdout, _ = runCommand("diff", "-json", "../../test_config.yaml", "../../test_config_lists.yaml")
+	assert.Equal(1, code)
+	assert.Contains(stdout, `"removed": [`)
+
+	code, _, stderr := runCommand("diff", "../../test_config.yaml", "../../missing.yaml")
+	assert.Equal(2, code)
+	assert.Contains(stderr, "goconfig: Failed to read json config file")
+}
+
+func TestEnvdoc(t *testing.T) {
+	assert := assertions.New(t)
+
+	code, stdout, _ := runCommand("envdoc", "../../test_config.yaml")
+	assert.Equal(0, code)
+	assert.Contains(stdout, "| `ROOT_FAMILY1_KEY1` | `root.family1.key1` | string | `test11` | "+
+		"(not overridable via env, set in config file) |\n")
+
+	code, stdout, _ = runCommand("envdoc", "-format", "dotenv", "../../test_config.yaml")
+	assert.Equal(0, code)
+	assert.Contains(stdout, "# root.family3.key1 (bool)\n# not overridable via env, set in config file\n# ROOT_FAMILY3_KEY1=true\n")
+
+	code, _, _ = runCommand("envdoc", "-format", "html", "../../test_config.yaml")
+	assert.Equal(2, code)
+}
+
+func TestUnknownCommand(t *testing.T) {
+	assert := assertions.New(t)
+
+	code, _, stderr := runCommand("explode")
+	assert.Equal(2, code)
+	assert.Contains(stderr, `unknown command "explode"`)
+
+	code, _, stderr = runCommand()
+	assert.Equal(2, code)
+	assert.Contains(stderr, "Usage: goconfig <command>")
+}
@@ -1,21 +1,65 @@
 package config
 
 import (
+	"context"
 	"encoding/base64"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
-	"os"
 	"strconv"
 	"strings"
+	"sync"
 
-	"sigs.k8s.io/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 // Config represents storage of properties that were read from file.
 type Config struct {
 	properties map[string]interface{}
+	filePath   string
+	format     int
+	lines      map[string]int
+	keyFiles   map[string]string
+	health     []SourceStatus
+	dotenv     map[string]dotenvVar
+	logger     Logger
+	propsMu    sync.RWMutex
+
+	ambiguityCheck  int
+	caseInsensitive bool
+	noExpansion     []string
+	expandNone      bool
+	schema          *Schema
+	strictKeys      bool
+	directories     []directorySource
+	args            []string
+	remote          *remoteSource
+	remoteOpts      *remoteOptions
+	providers       []Provider
+	allowPartial    bool
+	// providerProps holds the last properties loaded from every provider,
+	// they are used on reload in place of the ones of failed providers
+	providerProps []map[string]interface{}
+	plainIncludes bool
+	// includes holds paths and glob patterns of the files included by config
+	// file, see includeResolver
+	includes []string
+
+	mu           sync.RWMutex
+	aliases      []keyAlias
+	registered   []DeprecatedKey
+	deprecations map[string]*DeprecatedKey
+	expected     []expectedKey
+	declared     map[string]bool
+	descriptions map[string]string
+	flagSets     []*flag.FlagSet
+	argsParsed   map[string]string
+	flagValues   sync.Map
+
+	// consumed holds keys requested by getters, it has its own lock to keep
+	// getters from contending for mu.
+	consumed sync.Map
 }
 
 const (
@@ -23,35 +67,158 @@ const (
 	Yaml = iota
 	// Json specifies config file format. To be used in NewConfig constructor.
 	Json
+	// Toml specifies config file format. To be used in NewConfig constructor.
+	Toml
+	// Properties specifies config file format: Java .properties. To be used in NewConfig constructor.
+	Properties
+	// Ini specifies config file format. To be used in NewConfig constructor.
+	Ini
+	// Jsonc specifies config file format: JSON with comments and trailing commas.
+	// To be used in NewConfig constructor. Parsed the same way as config.Json5.
+	Jsonc
+	// Json5 specifies config file format: JSON5 allowing comments, trailing commas,
+	// unquoted keys and single-quoted strings. To be used in NewConfig constructor.
+	Json5
 )
 
+// Auto makes NewConfig detect config file format by the file extension,
+// see RegisterFormat for the extensions of built-in formats.
+const Auto = -1
+
 // NewConfig builds Config structure reading the file from path provided.
-// Argument format is one of the constants: config.Yaml or config.Json.
-func NewConfig(filePath string, format int) *Config {
-	configHolder := Config{}
-	plane, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		log.Panicf("Failed to read json config file: %v", err)
+// Argument format is one of the constants: config.Yaml, config.Json, config.Toml,
+// config.Properties, config.Ini, config.Jsonc, config.Json5, config.Auto or
+// the value returned by RegisterFormat for custom formats.
+// Optional arguments customize loading, see Option.
+func NewConfig(filePath string, format int, opts ...Option) *Config {
+	configHolder := Config{filePath: filePath, format: format, logger: log.Default()}
+	for _, opt := range opts {
+		opt(&configHolder)
+	}
+	configHolder.setLoaded(configHolder.load(context.Background()))
+	return &configHolder
+}
+
+// load reads config file, directories and providers into new Config holding
+// only the properties and the settings affecting their loading, then runs the
+// checks of loaded properties. It panics on errors the same way NewConfig does.
+// If partial loading is allowed, see LoadContext, failed providers contribute
+// the properties of their last successful load or are skipped if there is none.
+func (c *Config) load(ctx context.Context) *Config {
+	loaded := &Config{
+		filePath:        c.filePath,
+		logger:          c.logger,
+		ambiguityCheck:  c.ambiguityCheck,
+		caseInsensitive: c.caseInsensitive,
+		schema:          c.schema,
+	}
+	loaded.keyFiles = make(map[string]string)
+	resolver := c.newIncludeResolver(loaded.keyFiles)
+	originalConfigMap, root := c.readConfigFile(ctx, resolver)
+	loaded.includes = resolver.patterns
+	if c.filePath != "" {
+		loaded.health = append(loaded.health, SourceStatus{Name: "file " + c.filePath, Loaded: true})
 	}
-	switch format {
-	case Yaml:
-		plane, err = yaml.YAMLToJSON(plane)
+	for _, dir := range c.directories {
+		dirProps, err := readDirectory(dir.path, dir.parseFiles, "", loaded.keyFiles)
 		if err != nil {
-			log.Panicf("Failed to convert yaml config file to json: %v", err)
+			log.Panicf("Failed to read config directory: %v", err)
 		}
-		break
-	case Json:
-		break
-	default:
-		log.Panicf("Unknown config format: %v (allowed values config.Yaml, config.Json)", format)
+		originalConfigMap = mergeProps(originalConfigMap, dirProps)
+		loaded.health = append(loaded.health, SourceStatus{Name: dir.String(), Loaded: true})
 	}
+	results, statuses := loadProviders(ctx, c.providers)
+	c.propsMu.RLock()
+	previous := c.providerProps
+	c.propsMu.RUnlock()
+	for i, status := range statuses {
+		if status.Err != nil {
+			if !c.allowPartial {
+				log.Panicf("Failed to load config from %s: %v", status.Name, status.Err)
+			}
+			if i >= len(previous) || previous[i] == nil {
+				continue
+			}
+			results[i] = previous[i]
+		}
+		recordKeyFiles("", results[i], status.Name, loaded.keyFiles)
+		// results are kept for reloads, sections merged into must not be shared
+		originalConfigMap = mergeProps(originalConfigMap, cloneProps(results[i]))
+	}
+	loaded.health = append(loaded.health, statuses...)
+	loaded.providerProps = results
+	loaded.properties = originalConfigMap
+	loaded.normalizeProperties()
+	if loaded.caseInsensitive {
+		keyFiles := make(map[string]string, len(loaded.keyFiles))
+		for key, path := range loaded.keyFiles {
+			keyFiles[strings.ToLower(key)] = path
+		}
+		loaded.keyFiles = keyFiles
+	}
+	loaded.indexLines(root)
+	loaded.checkAmbiguousKeys()
+	loaded.checkSchema()
+	return loaded
+}
+
+// setLoaded replaces properties with the ones of loaded Config and checks
+// whether the new properties use deprecated keys.
+func (c *Config) setLoaded(loaded *Config) {
+	c.propsMu.Lock()
+	c.properties = loaded.properties
+	c.lines = loaded.lines
+	c.keyFiles = loaded.keyFiles
+	c.health = loaded.health
+	c.providerProps = loaded.providerProps
+	c.includes = loaded.includes
+	c.propsMu.Unlock()
+	c.checkDeprecations()
+}
 
+// readConfigFile reads and parses config file or remote document. It returns
+// the parsed document for line indexing if its format can be indexed. Config
+// built from providers only has no file, empty properties are returned then.
+func (c *Config) readConfigFile(ctx context.Context, resolver *includeResolver) (map[string]interface{}, *yamlv3.Node) {
+	if c.filePath == "" {
+		return make(map[string]interface{}), nil
+	}
+	var plane []byte
+	var err error
+	if c.remote != nil {
+		if plane, err = c.remote.fetch(ctx, c.logger); err != nil {
+			log.Panicf("Failed to fetch remote config: %v", err)
+		}
+	} else if plane, err = ioutil.ReadFile(c.filePath); err != nil {
+		log.Panicf("Failed to read json config file: %v", err)
+	}
+	format := resolveFormat(c.format, c.filePath)
+	// line numbers can be indexed only for the formats yaml parser can read
+	var root *yamlv3.Node
 	var originalConfigMap map[string]interface{}
-	if err := json.Unmarshal(plane, &originalConfigMap); err != nil {
-		log.Panicf("Failed to unmarshal json config file: %v", err)
+	if format == Yaml || format == Json {
+		originalConfigMap, root, err = parseIndexed(format, plane)
+	} else {
+		originalConfigMap, err = parseWithFormat(format, plane)
 	}
-	configHolder.properties = originalConfigMap
-	return &configHolder
+	if err != nil {
+		log.Panicf("Failed to parse config file: %v", err)
+	}
+	// includes of remote documents are not resolved
+	if c.remote == nil {
+		originalConfigMap, err = resolver.resolveIncludes(originalConfigMap, c.filePath, "", []string{c.filePath})
+		if err != nil {
+			log.Panicf("Failed to include config file: %v", err)
+		}
+	}
+	return originalConfigMap, root
+}
+
+// props returns properties tree, which is replaced as a whole on Reload.
+func (c *Config) props() map[string]interface{} {
+	c.propsMu.RLock()
+	defer c.propsMu.RUnlock()
+	return c.properties
 }
 
 // GetSecret returns value read from property and decoded from base64.
@@ -64,7 +231,7 @@ func (c *Config) GetSecret(key string) string {
 	prop := c.GetProp(key)
 	var strProp string
 	if prop == nil {
-		strProp = readStringFromEnv(key)
+		strProp = c.readStringFromEnv(key)
 	} else {
 		strProp = prop.(string)
 	}
@@ -106,7 +273,7 @@ func (c *Config) RequireSecret(key string) string {
 func (c *Config) GetString(key string, defaultVal ...string) string {
 	prop := c.GetProp(key)
 	if prop == nil {
-		return readStringFromEnv(key, defaultVal...)
+		return c.readStringFromEnv(key, defaultVal...)
 	}
 	strProp := fmt.Sprintf("%v", prop)
 	return strProp
@@ -124,7 +291,7 @@ func (c *Config) RequireString(key string) string {
 	prop := c.GetProp(key)
 	var strVal string
 	if prop == nil {
-		strVal = readStringFromEnv(key)
+		strVal = c.readStringFromEnv(key)
 	} else {
 		strVal = fmt.Sprintf("%v", prop)
 	}
@@ -146,7 +313,7 @@ func (c *Config) RequireString(key string) string {
 func (c *Config) GetBool(key string, defaultVal ...bool) bool {
 	prop := c.GetProp(key)
 	if prop == nil {
-		strVal := readStringFromEnv(key)
+		strVal := c.readStringFromEnv(key)
 		if strVal != "" {
 			return strings.EqualFold("true", strVal)
 		}
@@ -155,7 +322,7 @@ func (c *Config) GetBool(key string, defaultVal ...bool) bool {
 		}
 		return false
 	}
-	return prop.(bool)
+	return propToBool(key, prop)
 }
 
 // RequireBool returns bool value read from property.
@@ -170,14 +337,14 @@ func (c *Config) RequireBool(key string) bool {
 	prop := c.GetProp(key)
 	var strVal string
 	if prop == nil {
-		strVal = readStringFromEnv(key)
+		strVal = c.readStringFromEnv(key)
 		if strVal == "" {
 			log.Panicf("Required boolean property %v is not present", key)
 		} else {
 			return strings.EqualFold("true", strVal)
 		}
 	}
-	return prop.(bool)
+	return propToBool(key, prop)
 }
 
 // GetInt returns int value read from property.
@@ -192,7 +359,7 @@ func (c *Config) RequireBool(key string) bool {
 func (c *Config) GetInt(key string, defaultVal ...int) int {
 	prop := c.GetProp(key)
 	if prop == nil {
-		strVal := readStringFromEnv(key)
+		strVal := c.readStringFromEnv(key)
 		if strVal != "" {
 			if res, err := strconv.Atoi(strVal); err != nil {
 				log.Panicf("Failed to convert env value for key %s to int: %s", key, err)
@@ -205,7 +372,7 @@ func (c *Config) GetInt(key string, defaultVal ...int) int {
 		}
 		return 0
 	}
-	return int(prop.(float64))
+	return propToInt(key, prop)
 }
 
 // RequireInt returns int value read from property.
@@ -219,7 +386,7 @@ func (c *Config) GetInt(key string, defaultVal ...int) int {
 func (c *Config) RequireInt(key string) int {
 	prop := c.GetProp(key)
 	if prop == nil {
-		strVal := readStringFromEnv(key)
+		strVal := c.readStringFromEnv(key)
 		if strVal != "" {
 			if res, err := strconv.Atoi(strVal); err != nil {
 				log.Panicf("Failed to convert env value for key %s to int: %s", key, err)
@@ -229,7 +396,7 @@ func (c *Config) RequireInt(key string) int {
 		}
 		log.Panicf("Failed to find required property for key %s", key)
 	}
-	return int(prop.(float64))
+	return propToInt(key, prop)
 }
 
 // GetFloat64 returns float64 value read from property.
@@ -244,7 +411,7 @@ func (c *Config) RequireInt(key string) int {
 func (c *Config) GetFloat64(key string, defaultVal ...float64) float64 {
 	prop := c.GetProp(key)
 	if prop == nil {
-		strVal := readStringFromEnv(key)
+		strVal := c.readStringFromEnv(key)
 		if strVal != "" {
 			if res, err := strconv.ParseFloat(strVal, 64); err != nil {
 				log.Panicf("Failed to convert env value for key %s to float64: %s", key, err)
@@ -257,7 +424,7 @@ func (c *Config) GetFloat64(key string, defaultVal ...float64) float64 {
 		}
 		return 0
 	}
-	return prop.(float64)
+	return propToFloat(key, prop, 64)
 }
 
 // RequireFloat64 returns float64 value read from property.
@@ -272,7 +439,7 @@ func (c *Config) RequireFloat64(key string) float64 {
 	prop := c.GetProp(key)
 	var strVal string
 	if prop == nil {
-		strVal = readStringFromEnv(key)
+		strVal = c.readStringFromEnv(key)
 		if strVal == "" {
 			log.Panicf("Required float64 property %v is not present", key)
 		} else {
@@ -283,7 +450,7 @@ func (c *Config) RequireFloat64(key string) float64 {
 			}
 		}
 	}
-	return prop.(float64)
+	return propToFloat(key, prop, 64)
 }
 
 // GetFloat32 returns float32 value read from property.
@@ -298,7 +465,7 @@ func (c *Config) RequireFloat64(key string) float64 {
 func (c *Config) GetFloat32(key string, defaultVal ...float32) float32 {
 	prop := c.GetProp(key)
 	if prop == nil {
-		strVal := readStringFromEnv(key)
+		strVal := c.readStringFromEnv(key)
 		if strVal != "" {
 			if res, err := strconv.ParseFloat(strVal, 32); err != nil {
 				log.Panicf("Failed to convert env value for key %s to float64: %s", key, err)
@@ -311,7 +478,7 @@ func (c *Config) GetFloat32(key string, defaultVal ...float32) float32 {
 		}
 		return 0
 	}
-	return float32(prop.(float64))
+	return float32(propToFloat(key, prop, 32))
 }
 
 // RequireFloat32 returns float32 value read from property.
@@ -326,7 +493,7 @@ func (c *Config) RequireFloat32(key string) float32 {
 	prop := c.GetProp(key)
 	var strVal string
 	if prop == nil {
-		strVal = readStringFromEnv(key)
+		strVal = c.readStringFromEnv(key)
 		if strVal == "" {
 			log.Panicf("Required float32 property %v is not present", key)
 		} else {
@@ -337,35 +504,86 @@ func (c *Config) RequireFloat32(key string) float32 {
 			}
 		}
 	}
-	return float32(prop.(float64))
+	return float32(propToFloat(key, prop, 32))
 }
 
 // GetProp returns value read from property as interface{}.
 // The function will not try to lookup environment variable if property is missing.
 // If no property found for the key the function returns nil.
+//
+// String values may reference env variables and other properties as ${ENV_VAR},
+// ${ENV_VAR:-default} or ${key.path}. References are expanded on every lookup,
+// so all typed getters return expanded values. Use '$${' for literal '${'.
 func (c *Config) GetProp(key string) interface{} {
-	return findPropInMap(key, c.properties)
+	return c.expandProp(key, c.getRawProp(key), nil, true)
+}
+
+// getRawProp returns value of the property or its aliases without expansion.
+// Command-line flags take precedence over config file.
+func (c *Config) getRawProp(key string) interface{} {
+	return c.findRawProp(key, true)
+}
+
+// findRawProp is getRawProp recording the key as consumed only if track is
+// true, so values read for tooling like Diff don't hide unknown keys.
+func (c *Config) findRawProp(key string, track bool) interface{} {
+	if track {
+		c.markConsumed(c.normalizeKey(key))
+	}
+	candidates := c.keyCandidates(key)
+	for _, candidate := range candidates {
+		if val, found := c.lookupFlag(candidate); found {
+			return val
+		}
+	}
+	for _, candidate := range candidates {
+		if prop := findPropInMap(candidate, c.props()); prop != nil {
+			if track {
+				c.markConsumed(candidate)
+			}
+			return prop
+		}
+	}
+	return nil
 }
 
 func findPropInMap(key string, props map[string]interface{}) interface{} {
+	val, _ := lookupProp(key, props)
+	return resolveProp(val)
+}
+
+// lookupProp searches the key in the properties map. Literal key is checked
+// first, then the key is split on every dot from left to right, so both nested
+// sections and literal dotted keys (e.g. 'family3.key1') can be resolved.
+// Segments of the key may address list elements, see resolveIndexed.
+// The second returned value reports whether the key is present at all.
+func lookupProp(key string, props map[string]interface{}) (interface{}, bool) {
 	if val, ok := props[key]; ok {
-		return resolveProp(val)
-	}
-	dotIdx := strings.Index(key, ".")
-	if dotIdx != -1 {
-		prefix := key[:dotIdx]
-		suffix := key[dotIdx+1:]
-		if val, ok := props[prefix]; ok {
-			var foundProp interface{}
-			if nextSubmap, ok := val.(map[string]interface{}); ok {
-				foundProp = findPropInMap(suffix, nextSubmap)
-			}
-			if foundProp != nil {
-				return foundProp
+		return val, true
+	}
+	for splitIdx := 0; splitIdx < len(key); {
+		nextIdx := strings.Index(key[splitIdx:], ".")
+		if nextIdx == -1 {
+			splitIdx = len(key)
+		} else {
+			splitIdx += nextIdx
+		}
+		prefix := key[:splitIdx]
+		suffix := ""
+		if splitIdx < len(key) {
+			suffix = key[splitIdx+1:]
+		}
+		name, indices, ok := parseIndexedSegment(prefix)
+		if ok && (suffix != "" || len(indices) > 0) {
+			if val, exists := props[name]; exists {
+				if found, isFound := resolveIndexed(val, indices, suffix); isFound {
+					return found, true
+				}
 			}
 		}
+		splitIdx++
 	}
-	return nil
+	return nil, false
 }
 
 func resolveProp(val interface{}) interface{} {
@@ -378,12 +596,80 @@ func resolveProp(val interface{}) interface{} {
 	return val
 }
 
-func readStringFromEnv(propertyKey string, defaultVal ...string) string {
-	envName := strings.ToUpper(propertyKey)
-	envName = strings.ReplaceAll(envName, ".", "_")
-	env := os.Getenv(envName)
+// propToBool converts property value to bool. String values are compared
+// with 'true' ignoring case, same as values of env variables.
+func propToBool(key string, prop interface{}) bool {
+	if strVal, ok := prop.(string); ok {
+		return strings.EqualFold("true", strVal)
+	}
+	checkNotList(key, prop)
+	return prop.(bool)
+}
+
+// propToInt converts property value to int. String values are parsed.
+func propToInt(key string, prop interface{}) int {
+	if strVal, ok := prop.(string); ok {
+		res, err := strconv.Atoi(strVal)
+		if err != nil {
+			log.Panicf("Failed to convert value for key %s to int: %s", key, err)
+		}
+		return res
+	}
+	checkNotList(key, prop)
+	return int(prop.(float64))
+}
+
+// propToFloat converts property value to float with the given bit size. String values are parsed.
+func propToFloat(key string, prop interface{}, bitSize int) float64 {
+	if strVal, ok := prop.(string); ok {
+		res, err := strconv.ParseFloat(strVal, bitSize)
+		if err != nil {
+			log.Panicf("Failed to convert value for key %s to float%d: %s", key, bitSize, err)
+		}
+		return res
+	}
+	checkNotList(key, prop)
+	return prop.(float64)
+}
+
+// checkNotList panics with explanatory message if the property holds a list,
+// e.g. the one collected by wildcard key. Lists are read with slice getters.
+func checkNotList(key string, prop interface{}) {
+	if _, ok := prop.([]interface{}); ok {
+		log.Panicf("Property %s holds a list, use slice getters like GetIntSlice to read it", key)
+	}
+}
+
+func (c *Config) readStringFromEnv(propertyKey string, defaultVal ...string) string {
+	var env string
+	for _, key := range c.keyCandidates(propertyKey) {
+		if env = c.getEnv(envVarName(key)); env != "" {
+			break
+		}
+	}
 	if env == "" && len(defaultVal) > 0 {
 		return defaultVal[0]
 	}
 	return env
 }
+
+// EnvVarName returns the name of the environment variable the property is read
+// from if it's missing in config file, e.g. 'MY_TEST_PROPERTY1' for the key
+// 'my.test.property1'. Empty name is returned for keys having no env variable.
+func EnvVarName(propertyKey string) string {
+	return envVarName(propertyKey)
+}
+
+// envVarName translates property key to the environment variable name,
+// e.g. 'my.test.property1' is translated to 'MY_TEST_PROPERTY1' and
+// 'servers[0].host' is translated to 'SERVERS_0_HOST'. Keys with negative
+// or wildcard indices have no env variable, empty name is returned for them.
+func envVarName(propertyKey string) string {
+	if strings.Contains(propertyKey, "[-") || strings.Contains(propertyKey, "[*]") {
+		return ""
+	}
+	envName := strings.ToUpper(propertyKey)
+	envName = strings.ReplaceAll(envName, "[", "_")
+	envName = strings.ReplaceAll(envName, "]", "")
+	return strings.ReplaceAll(envName, ".", "_")
+}
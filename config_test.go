@@ -40,7 +40,7 @@ func TestConfig_UnsupportedFileFormat(t *testing.T) {
 			t.Errorf("Expected panic on unsupported file format")
 		}
 	}()
-	_ = NewConfig("./config_test.go", 5)
+	_ = NewConfig("./config_test.go", 99)
 }
 
 func TestConfig_ParsingErr(t *testing.T) {
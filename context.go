@@ -0,0 +1,223 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sourcePollInterval is the interval of checks for changes of file and directory sources.
+const sourcePollInterval = time.Second
+
+// SourceStatus describes the result of loading the source of properties.
+type SourceStatus struct {
+	// Name describes the source, e.g. 'file ./config.yaml'.
+	Name string
+	// Loaded is true if properties of the source were loaded.
+	Loaded bool
+	// Err is the error of loading, nil if the source was loaded.
+	Err error
+	// Duration is the time spent loading the source, zero for config file and
+	// directories of NewConfig.
+	Duration time.Duration
+}
+
+// LoadError is returned from LoadContext if none of the sources was loaded.
+type LoadError struct {
+	// Failed lists statuses of all the sources.
+	Failed []SourceStatus
+}
+
+// PartialLoadError is returned from LoadContext together with Config if some
+// of the sources failed to load. Config holds properties of the loaded sources.
+type PartialLoadError struct {
+	// Failed lists statuses of the sources that failed to load.
+	Failed []SourceStatus
+}
+
+// LoadContext builds Config reading properties from the sources, which are
+// loaded concurrently. Sources passed later take precedence over earlier ones.
+// Config file and directories are read with NewFileSource and NewDirectorySource,
+// any Provider can be used as the source as well.
+//
+// Unlike NewConfig, LoadContext doesn't panic. It returns the context error if
+// the context is done before all sources are loaded, LoadError if none of the
+// sources was loaded and PartialLoadError together with Config if some of them
+// failed. Sources not honoring the context are abandoned when it's done, their
+// results are ignored. Other errors, e.g. schema violations, are returned as is.
+//
+// Statuses of the sources are reported by Health; ReloadContext and Reload load
+// the sources again and accept partial results the same way, except that failed
+// sources keep the properties of their last successful load.
+func LoadContext(ctx context.Context, sources ...Provider) (*Config, error) {
+	return LoadContextWithOptions(ctx, sources)
+}
+
+// LoadContextWithOptions is LoadContext applying the options the same way as NewConfig.
+func LoadContextWithOptions(ctx context.Context, sources []Provider, opts ...Option) (config *Config, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			config, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+	configHolder := Config{logger: log.Default(), providers: sources, allowPartial: true}
+	for _, opt := range opts {
+		opt(&configHolder)
+	}
+	loaded := configHolder.load(ctx)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	failed := failedSources(loaded.health)
+	if len(sources) > 0 && len(failed) == len(sources) {
+		return nil, &LoadError{Failed: failed}
+	}
+	configHolder.setLoaded(loaded)
+	if len(failed) > 0 {
+		return &configHolder, &PartialLoadError{Failed: failed}
+	}
+	return &configHolder, nil
+}
+
+// Health returns statuses of all the sources of the last load or reload in order
+// of precedence: config file, directories and providers.
+func (c *Config) Health() []SourceStatus {
+	c.propsMu.RLock()
+	defer c.propsMu.RUnlock()
+	return append([]SourceStatus(nil), c.health...)
+}
+
+// Healthy reports whether all the sources of the last load or reload were loaded.
+func (c *Config) Healthy() bool {
+	for _, status := range c.Health() {
+		if !status.Loaded {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *LoadError) Error() string {
+	return "failed to load config: " + formatFailures(e.Failed)
+}
+
+func (e *PartialLoadError) Error() string {
+	return "failed to load some of config sources: " + formatFailures(e.Failed)
+}
+
+// String formats the status in human-readable form.
+func (s SourceStatus) String() string {
+	if s.Err != nil {
+		return fmt.Sprintf("%s: %v", s.Name, s.Err)
+	}
+	return s.Name + ": ok"
+}
+
+func failedSources(statuses []SourceStatus) []SourceStatus {
+	var failed []SourceStatus
+	for _, status := range statuses {
+		if status.Err != nil {
+			failed = append(failed, status)
+		}
+	}
+	return failed
+}
+
+func formatFailures(failed []SourceStatus) string {
+	messages := make([]string, 0, len(failed))
+	for _, status := range failed {
+		messages = append(messages, status.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// loadProviders loads the providers concurrently. Loading of every provider
+// is abandoned when the context is done.
+func loadProviders(ctx context.Context, providers []Provider) ([]map[string]interface{}, []SourceStatus) {
+	results := make([]map[string]interface{}, len(providers))
+	statuses := make([]SourceStatus, len(providers))
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			start := time.Now()
+			results[i], statuses[i].Err = loadProvider(ctx, provider)
+			statuses[i].Name = providerName(provider)
+			statuses[i].Loaded = statuses[i].Err == nil
+			statuses[i].Duration = time.Since(start)
+		}(i, provider)
+	}
+	wg.Wait()
+	return results, statuses
+}
+
+func loadProvider(ctx context.Context, provider Provider) (map[string]interface{}, error) {
+	type result struct {
+		props map[string]interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		props, err := provider.Load(ctx)
+		done <- result{props: props, err: err}
+	}()
+	select {
+	case res := <-done:
+		return res.props, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type fileSource struct {
+	path   string
+	format int
+}
+
+// NewFileSource creates Provider reading the config file the same way as
+// NewConfig does, including include directives. Argument format is the same
+// as for NewConfig. Watch checks the file for changes every second.
+func NewFileSource(path string, format int) Provider {
+	return &fileSource{path: path, format: format}
+}
+
+// Load reads and parses the file.
+func (f *fileSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	format := f.format
+	if format == Auto {
+		detected, ok := FormatByExtension(f.path)
+		if !ok {
+			return nil, fmt.Errorf("unknown config file extension: %s", f.path)
+		}
+		format = detected
+	} else if getFormat(format) == nil {
+		return nil, fmt.Errorf("unknown config format: %v", format)
+	}
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	props, err := parseWithFormat(format, data)
+	if err != nil {
+		return nil, err
+	}
+	resolver := &includeResolver{keys: []string{includeKey}, files: make(map[string]string)}
+	return resolver.resolveIncludes(props, f.path, "", []string{f.path})
+}
+
+// Watch returns channel receiving the events for every property changed in the file.
+func (f *fileSource) Watch(ctx context.Context) <-chan Event {
+	return pollEvents(ctx, sourcePollInterval, f.Load)
+}
+
+func (f *fileSource) String() string {
+	return "file " + f.path
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"context"
+	"errors"
+	assertions "github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// blockingProvider ignores the context and blocks until released.
+type blockingProvider struct {
+	release chan struct{}
+}
+
+func (p *blockingProvider) Load(context.Context) (map[string]interface{}, error) {
+	<-p.release
+	return map[string]interface{}{"slow": "value"}, nil
+}
+
+func (p *blockingProvider) Watch(context.Context) <-chan Event {
+	return nil
+}
+
+// failingProvider fails to load while err is set.
+type failingProvider struct {
+	err error
+}
+
+func (p *failingProvider) Load(context.Context) (map[string]interface{}, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return map[string]interface{}{"db": map[string]interface{}{"host": "provided"}}, nil
+}
+
+func (p *failingProvider) Watch(context.Context) <-chan Event {
+	return nil
+}
+
+func (p *failingProvider) String() string {
+	return "failing"
+}
+
+func TestLoadContext(t *testing.T) {
+	assert := assertions.New(t)
+
+	config, err := LoadContext(context.Background(),
+		NewFileSource("./test_config.yaml", Auto),
+		NewDirectorySource(writeFiles(t, map[string]string{"extra.key": "from-dir"}), false),
+	)
+	assert.NoError(err)
+	testConfigPositiveCases(t, config)
+	assert.Equal("from-dir", config.GetString("extra.key"))
+	assert.True(config.Healthy())
+	assert.Len(config.Health(), 2)
+	assert.Equal("file ./test_config.yaml", config.Explain("root.family1.key1").Source.Name)
+}
+
+func TestLoadContext_Deadline(t *testing.T) {
+	assert := assertions.New(t)
+	provider := &blockingProvider{release: make(chan struct{})}
+	defer close(provider.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	config, err := LoadContext(ctx, NewFileSource("./test_config.yaml", Yaml), provider)
+	assert.Nil(config)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+	assert.Less(int64(time.Since(start)), int64(time.Second))
+}
+
+func TestLoadContext_PartialErrors(t *testing.T) {
+	assert := assertions.New(t)
+	failing := &failingProvider{err: errors.New("connection refused")}
+
+	config, err := LoadContext(context.Background(), NewFileSource("./test_config.yaml", Yaml), failing)
+	var partial *PartialLoadError
+	assert.True(errors.As(err, &partial))
+	assert.EqualError(err, "failed to load some of config sources: failing: connection refused")
+	assert.Equal("test11", config.GetString("root.family1.key1"))
+	assert.False(config.Healthy())
+	health := config.Health()
+	assert.True(health[0].Loaded)
+	assert.Equal("failing", health[1].Name)
+	assert.False(health[1].Loaded)
+
+	failing.err = nil
+	assert.NoError(config.ReloadContext(context.Background()))
+	assert.Equal("provided", config.GetString("db.host"))
+	assert.True(config.Healthy())
+
+	failing.err = errors.New("timeout")
+	assert.True(errors.As(config.Reload(), &partial))
+	assert.Equal("provided", config.GetString("db.host"))
+	assert.False(config.Healthy())
+
+	config, err = LoadContext(context.Background(), NewFileSource("./missing.yaml", Yaml), failing)
+	assert.Nil(config)
+	var loadErr *LoadError
+	assert.True(errors.As(err, &loadErr))
+	assert.Len(loadErr.Failed, 2)
+}
+
+func TestConfig_ReloadContextPartial(t *testing.T) {
+	assert := assertions.New(t)
+	failing := &failingProvider{}
+	store := NewMemoryProvider(map[string]string{"app/name": "first"})
+
+	config, err := LoadContextWithOptions(context.Background(), []Provider{store, failing}, WithLogger(&testLogger{}))
+	assert.NoError(err)
+	assert.Equal("provided", config.GetString("db.host"))
+
+	// loaded providers are applied, the failed one keeps its last properties
+	failing.err = errors.New("timeout")
+	store.Set("app/name", "second")
+	var partial *PartialLoadError
+	assert.True(errors.As(config.Reload(), &partial))
+	assert.Equal("second", config.GetString("app.name"))
+	assert.Equal("provided", config.GetString("db.host"))
+	assert.Equal("failing", config.Explain("db.host").Source.Name)
+	assert.False(config.Healthy())
+
+	// config of NewProviderConfig keeps all properties on failure
+	failing.err = nil
+	strict := NewProviderConfig([]Provider{store, failing})
+	failing.err = errors.New("timeout")
+	store.Set("app/name", "third")
+	assert.Error(strict.Reload())
+	assert.Equal("second", strict.GetString("app.name"))
+}
+
+func TestNewRemoteSource(t *testing.T) {
+	assert := assertions.New(t)
+	handler := &configServer{body: "db:\n  host: remote\n", failures: 1}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	source := NewRemoteSource(server.URL+"/config.yaml", Auto, WithRemoteRetries(1, time.Millisecond))
+	config, err := LoadContext(context.Background(), NewFileSource("./test_config.yaml", Yaml), source)
+	assert.NoError(err)
+	assert.Equal("remote", config.GetString("db.host"))
+	assert.Equal("test11", config.GetString("root.family1.key1"))
+	assert.Equal("remote "+server.URL+"/config.yaml", config.Explain("db.host").Source.Name)
+}
+
+func TestNewRemoteSource_Canceled(t *testing.T) {
+	assert := assertions.New(t)
+	handler := &configServer{failures: 10}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	source := NewRemoteSource(server.URL+"/config.json", Auto, WithRemoteRetries(3, time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := source.Load(ctx)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+	assert.Less(int64(time.Since(start)), int64(time.Second))
+}
+
+func TestConfig_HealthOfNewConfig(t *testing.T) {
+	assert := assertions.New(t)
+	config := NewConfig("./test_config.yaml", Yaml)
+	assert.Equal([]SourceStatus{{Name: "file ./test_config.yaml", Loaded: true}}, config.Health())
+	assert.Equal("file ./test_config.yaml: ok", config.Health()[0].String())
+}
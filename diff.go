@@ -0,0 +1,198 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maskedValue replaces values of secret properties in diffs.
+const maskedValue = "******"
+
+// secretKeyWords mark properties holding secrets by the last segment of the key.
+var secretKeyWords = []string{"password", "passwd", "secret", "token", "credential", "apikey", "api_key", "private"}
+
+// Change describes the property added, removed or modified between two configs.
+type Change struct {
+	// Key is the fully qualified dotted key of the property.
+	Key string `json:"key"`
+	// Old is the value in the first config, nil for added properties.
+	Old interface{} `json:"old,omitempty"`
+	// New is the value in the second config, nil for removed properties.
+	New interface{} `json:"new,omitempty"`
+}
+
+// ConfigDiff holds the changes between two configs, each list is sorted by key.
+type ConfigDiff struct {
+	// From and To describe the compared configs, e.g. their file paths.
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Added    []Change `json:"added"`
+	Removed  []Change `json:"removed"`
+	Modified []Change `json:"modified"`
+}
+
+// Diff compares effective values of all properties of two configs, see AllKeys.
+// Values are resolved the same way as by GetProp, so references are expanded
+// and command-line flags are applied; reading them doesn't record the keys as
+// consumed, see UnknownKeys. Values of secret properties are masked, including
+// the ones nested in lists and sections: the ones declared with Expect as
+// config.TypeSecret in either config and the ones with keys containing words
+// like 'password', 'secret' or 'token'.
+func Diff(a, b *Config) ConfigDiff {
+	diff := ConfigDiff{From: a.filePath, To: b.filePath, Added: []Change{}, Removed: []Change{}, Modified: []Change{}}
+	secrets := a.secretKeys()
+	for key := range b.secretKeys() {
+		secrets[key] = true
+	}
+	mask := func(key string, val interface{}) interface{} {
+		return maskValue(key, val, secrets)
+	}
+
+	oldValues := a.effectiveValues()
+	newValues := b.effectiveValues()
+	for key, oldVal := range oldValues {
+		newVal, ok := newValues[key]
+		switch {
+		case !ok:
+			diff.Removed = append(diff.Removed, Change{Key: key, Old: mask(key, oldVal)})
+		case !reflect.DeepEqual(oldVal, newVal):
+			diff.Modified = append(diff.Modified, Change{Key: key, Old: mask(key, oldVal), New: mask(key, newVal)})
+		}
+	}
+	for key, newVal := range newValues {
+		if _, ok := oldValues[key]; !ok {
+			diff.Added = append(diff.Added, Change{Key: key, New: mask(key, newVal)})
+		}
+	}
+	for _, changes := range [][]Change{diff.Added, diff.Removed, diff.Modified} {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Key < changes[j].Key
+		})
+	}
+	return diff
+}
+
+// Empty reports whether configs have no differences.
+func (d ConfigDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// Unified formats the diff in unified style: removed values are prefixed with
+// '-', added ones with '+', changes of all kinds are sorted by key together.
+func (d ConfigDiff) Unified() string {
+	type line struct {
+		key  string
+		text string
+	}
+	var lines []line
+	for _, change := range d.Removed {
+		lines = append(lines, line{change.Key, "-" + formatChange(change.Key, change.Old)})
+	}
+	for _, change := range d.Modified {
+		lines = append(lines, line{change.Key, "-" + formatChange(change.Key, change.Old) +
+			"\n+" + formatChange(change.Key, change.New)})
+	}
+	for _, change := range d.Added {
+		lines = append(lines, line{change.Key, "+" + formatChange(change.Key, change.New)})
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].key < lines[j].key
+	})
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", d.From, d.To))
+	for _, l := range lines {
+		builder.WriteString(l.text + "\n")
+	}
+	return builder.String()
+}
+
+// JSON formats the diff as JSON object with 'from', 'to', 'added', 'removed'
+// and 'modified' fields, each change has 'key', 'old' and 'new' fields.
+func (d ConfigDiff) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// String formats the diff in unified style, see Unified.
+func (d ConfigDiff) String() string {
+	return d.Unified()
+}
+
+// effectiveValues returns values of all properties by their keys, resolved
+// the same way as by GetProp without recording the keys as consumed.
+func (c *Config) effectiveValues() map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, key := range c.AllKeys() {
+		values[key] = c.expandProp(key, c.findRawProp(key, false), nil, false)
+	}
+	return values
+}
+
+// secretKeys returns keys declared with Expect as config.TypeSecret.
+func (c *Config) secretKeys() map[string]bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	secrets := make(map[string]bool)
+	for _, exp := range c.expected {
+		if exp.valueType == TypeSecret {
+			secrets[c.normalizeKey(exp.key)] = true
+		}
+	}
+	return secrets
+}
+
+func isSecretKey(key string, secrets map[string]bool) bool {
+	if secrets[key] {
+		return true
+	}
+	last := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+	for _, word := range secretKeyWords {
+		if strings.Contains(last, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskValue replaces the value of secret property with maskedValue. Lists and
+// sections are copied with their secret elements masked, elements of lists are
+// checked by keys like 'users[0].password'.
+func maskValue(key string, val interface{}, secrets map[string]bool) interface{} {
+	if isSecretKey(key, secrets) {
+		return maskedValue
+	}
+	switch typed := val.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(typed))
+		for name, elem := range typed {
+			masked[name] = maskValue(key+"."+name, elem, secrets)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(typed))
+		for i, elem := range typed {
+			masked[i] = maskValue(fmt.Sprintf("%s[%d]", key, i), elem, secrets)
+		}
+		return masked
+	}
+	return val
+}
+
+func formatChange(key string, val interface{}) string {
+	return key + " = " + FormatValue(val)
+}
+
+// FormatValue formats the property value for output: strings as is and other
+// values, e.g. numbers, lists and sections, as JSON.
+func FormatValue(val interface{}) string {
+	if str, ok := val.(string); ok {
+		return str
+	}
+	encoded, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(encoded)
+}
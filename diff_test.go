@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/json"
+	assertions "github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	assert := assertions.New(t)
+	dir := writeFiles(t, map[string]string{
+		"old.yaml": "app:\n  name: demo\n  port: 8080\n  hosts: [a, b]\n" +
+			"db:\n  password: old-pass\n  key: b2xk\n  user: admin\n",
+		"new.yaml": "app:\n  name: demo\n  port: 9090\n  hosts: [a, b, c]\n  debug: true\n" +
+			"db:\n  password: new-pass\n  key: bmV3\n",
+	})
+	oldConfig := NewConfig(filepath.Join(dir, "old.yaml"), Yaml)
+	newConfig := NewConfig(filepath.Join(dir, "new.yaml"), Yaml).Expect("db.key", TypeSecret)
+
+	diff := Diff(oldConfig, newConfig)
+	assert.False(diff.Empty())
+	assert.Equal([]Change{{Key: "app.debug", New: true}}, diff.Added)
+	assert.Equal([]Change{{Key: "db.user", Old: "admin"}}, diff.Removed)
+	assert.Equal([]Change{
+		{Key: "app.hosts", Old: []interface{}{"a", "b"}, New: []interface{}{"a", "b", "c"}},
+		{Key: "app.port", Old: float64(8080), New: float64(9090)},
+		{Key: "db.key", Old: maskedValue, New: maskedValue},
+		{Key: "db.password", Old: maskedValue, New: maskedValue},
+	}, diff.Modified)
+
+	assert.Equal("--- "+filepath.Join(dir, "old.yaml")+"\n"+
+		"+++ "+filepath.Join(dir, "new.yaml")+"\n"+
+		"+app.debug = true\n"+
+		"-app.hosts = [\"a\",\"b\"]\n+app.hosts = [\"a\",\"b\",\"c\"]\n"+
+		"-app.port = 8080\n+app.port = 9090\n"+
+		"-db.key = ******\n+db.key = ******\n"+
+		"-db.password = ******\n+db.password = ******\n"+
+		"-db.user = admin\n", diff.Unified())
+
+	encoded, err := diff.JSON()
+	assert.NoError(err)
+	var decoded map[string]interface{}
+	assert.NoError(json.Unmarshal(encoded, &decoded))
+	assert.Equal([]interface{}{map[string]interface{}{"key": "app.debug", "new": true}}, decoded["added"])
+	assert.Equal([]interface{}{map[string]interface{}{"key": "db.user", "old": "admin"}}, decoded["removed"])
+
+	same := Diff(oldConfig, oldConfig)
+	assert.True(same.Empty())
+	encoded, err = same.JSON()
+	assert.NoError(err)
+	assert.Contains(string(encoded), `"added": []`)
+}
+
+func TestDiff_NestedSecrets(t *testing.T) {
+	assert := assertions.New(t)
+	dir := writeFiles(t, map[string]string{
+		"old.yaml": "users:\n  - name: a\n    password: hunter2\n" +
+			"ldap: [{url: ldap://a, bind: {token: abc}}]\nref: ${users[0].name}\n",
+		"new.yaml": "users:\n  - name: b\n    password: hunter3\n" +
+			"ldap: [{url: ldap://b, bind: {token: xyz}}]\nref: ${users[0].name}\n",
+	})
+	oldConfig := NewConfig(filepath.Join(dir, "old.yaml"), Yaml)
+	newConfig := NewConfig(filepath.Join(dir, "new.yaml"), Yaml)
+
+	diff := Diff(oldConfig, newConfig)
+	assert.Equal([]Change{
+		{Key: "ldap",
+			Old: []interface{}{map[string]interface{}{"url": "ldap://a", "bind": map[string]interface{}{"token": maskedValue}}},
+			New: []interface{}{map[string]interface{}{"url": "ldap://b", "bind": map[string]interface{}{"token": maskedValue}}}},
+		{Key: "ref", Old: "a", New: "b"},
+		{Key: "users",
+			Old: []interface{}{map[string]interface{}{"name": "a", "password": maskedValue}},
+			New: []interface{}{map[string]interface{}{"name": "b", "password": maskedValue}}},
+	}, diff.Modified)
+	assert.NotContains(diff.Unified(), "hunter")
+	assert.NotContains(diff.Unified(), "xyz")
+
+	// reading values for diff doesn't mark keys as consumed
+	assert.Len(oldConfig.UnknownKeys(), 3)
+}
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// kubernetesDataDir is the symlink Kubernetes swaps atomically when mounted
+// ConfigMap or Secret is updated. Entries starting with '..' are bookkeeping.
+const kubernetesDataDir = "..data"
+
+type directorySource struct {
+	path       string
+	parseFiles bool
+}
+
+// WithDirectory reads properties from the directory where each file name is
+// the key and the file contents is the value, the way Kubernetes mounts
+// ConfigMaps and Secrets. Dots in file names denote nested sections, e.g. file
+// 'db.host' is resolved by the key 'db.host'; subdirectories are nested sections.
+// Trailing newlines of the values are trimmed, entries starting with '..' (the
+// '..data' symlink and timestamped directories of Kubernetes) are ignored.
+//
+// If parseFiles is true, files with extensions of registered formats, e.g.
+// 'db.yaml', are parsed and stored as sections under the file name without
+// extension. Properties of directories take precedence over the config file,
+// directories passed later take precedence over earlier ones.
+// NewConfig panics if directory can't be read.
+func WithDirectory(dir string, parseFiles bool) Option {
+	return func(c *Config) {
+		c.directories = append(c.directories, directorySource{path: dir, parseFiles: parseFiles})
+	}
+}
+
+// Reload reads config file, directories and providers again and replaces all
+// properties at once, so getters running concurrently see either old or new
+// values. If loading fails, current properties are kept and the error is returned.
+func (c *Config) Reload() error {
+	return c.ReloadContext(context.Background())
+}
+
+// ReloadContext is Reload passing the context to providers, see LoadContext.
+// Config built by LoadContext accepts partial results the same way LoadContext
+// does: properties of the loaded providers are applied, failed providers keep
+// the properties of their last successful load, PartialLoadError is returned
+// and Health reports the failed providers. For other configs failure of any
+// provider keeps all current properties.
+func (c *Config) ReloadContext(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	loaded := c.load(ctx)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.setLoaded(loaded)
+	if failed := failedSources(loaded.health); len(failed) > 0 {
+		return &PartialLoadError{Failed: failed}
+	}
+	return nil
+}
+
+// NewDirectorySource creates Provider reading the directory the same way as
+// WithDirectory does. Watch checks the directory for changes every second.
+func NewDirectorySource(dir string, parseFiles bool) Provider {
+	return &directorySource{path: dir, parseFiles: parseFiles}
+}
+
+// Load reads properties of the directory.
+func (d *directorySource) Load(ctx context.Context) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return readDirectory(d.path, d.parseFiles, "", make(map[string]string))
+}
+
+// Watch returns channel receiving the events for every property changed in the directory.
+func (d *directorySource) Watch(ctx context.Context) <-chan Event {
+	return pollEvents(ctx, sourcePollInterval, d.Load)
+}
+
+func (d *directorySource) String() string {
+	return "directory " + d.path
+}
+
+// WatchReload checks config file, included files and directories for changes
+// every interval and calls Reload when they change. For directories mounted by
+// Kubernetes the swap of '..data' symlink is detected, for other directories the names,
+// sizes and modification times of files are compared. Config built with
+// NewRemoteConfig is reloaded every interval. Reload errors are logged and
+// reload is retried every interval until it succeeds.
+// Call the returned function to stop watching, it waits for reload in progress.
+func (c *Config) WatchReload(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	last := c.fingerprint()
+	go func() {
+		defer close(finished)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// remote documents are checked by conditional requests of Reload
+				current := c.fingerprint()
+				if current == last && c.remote == nil {
+					continue
+				}
+				// failed reload is retried on the next tick
+				if err := c.Reload(); err != nil {
+					c.logger.Printf("Failed to reload config: %v", err)
+					continue
+				}
+				last = current
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// fingerprint describes the state of config file, the files it includes and
+// directories, it changes whenever their contents are changed. Glob patterns
+// of includes are matched again, so added and removed files are detected too.
+func (c *Config) fingerprint() string {
+	var builder strings.Builder
+	if info, err := os.Stat(c.filePath); err == nil {
+		builder.WriteString(fmt.Sprintf("%s %d %d\n", c.filePath, info.Size(), info.ModTime().UnixNano()))
+	}
+	c.propsMu.RLock()
+	includes := c.includes
+	c.propsMu.RUnlock()
+	for _, pattern := range includes {
+		matches, _ := matchIncludes(pattern)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil {
+				builder.WriteString(fmt.Sprintf("%s %d %d\n", match, info.Size(), info.ModTime().UnixNano()))
+			} else {
+				builder.WriteString(match + " missing\n")
+			}
+		}
+	}
+	for _, dir := range c.directories {
+		if target, err := os.Readlink(filepath.Join(dir.path, kubernetesDataDir)); err == nil {
+			builder.WriteString(fmt.Sprintf("%s -> %s\n", dir.path, target))
+			continue
+		}
+		_ = filepath.Walk(dir.path, func(path string, info os.FileInfo, err error) error {
+			if err == nil {
+				builder.WriteString(fmt.Sprintf("%s %d %d\n", path, info.Size(), info.ModTime().UnixNano()))
+			}
+			return nil
+		})
+	}
+	return builder.String()
+}
+
+// readDirectory reads properties from the files of the directory. Paths of
+// the files are stored to files by the keys of their properties.
+func readDirectory(dir string, parseFiles bool, prefix string, files map[string]string) (map[string]interface{}, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	props := make(map[string]interface{})
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "..") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		// os.Stat follows symlinks pointing into '..data'
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			nested, err := readDirectory(path, parseFiles, joinKey(prefix, name), files)
+			if err != nil {
+				return nil, err
+			}
+			setNestedProp(props, name, nested)
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key := name
+		var val interface{} = strings.TrimRight(string(data), "\r\n")
+		if format, ok := FormatByExtension(name); ok && parseFiles {
+			key = strings.TrimSuffix(name, filepath.Ext(name))
+			if val, err = parseWithFormat(format, data); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		setNestedProp(props, key, val)
+		recordKeyFiles(joinKey(prefix, key), val, path, files)
+	}
+	return props, nil
+}
+
+// recordKeyFiles stores the source name by the keys of all properties of the value.
+func recordKeyFiles(key string, val interface{}, name string, files map[string]string) {
+	if section, ok := val.(map[string]interface{}); ok {
+		walkProps(key, section, func(nestedKey string, _ interface{}) {
+			files[nestedKey] = name
+		})
+	} else {
+		files[key] = name
+	}
+}
+
+// mergeProps merges properties of the override into the base recursively.
+// Sections are merged, other values of the override replace the base ones.
+func mergeProps(base, override map[string]interface{}) map[string]interface{} {
+	if base == nil {
+		base = make(map[string]interface{})
+	}
+	for key, val := range override {
+		baseSection, baseIsMap := base[key].(map[string]interface{})
+		section, isMap := val.(map[string]interface{})
+		if baseIsMap && isMap {
+			base[key] = mergeProps(baseSection, section)
+		} else {
+			base[key] = val
+		}
+	}
+	return base
+}
+
+// cloneProps copies the sections of properties recursively, so merging into
+// the copy doesn't modify the original.
+func cloneProps(props map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(props))
+	for key, val := range props {
+		if section, ok := val.(map[string]interface{}); ok {
+			val = cloneProps(section)
+		}
+		clone[key] = val
+	}
+	return clone
+}
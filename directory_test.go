@@ -0,0 +1,144 @@
+package config
+
+import (
+	"context"
+	"errors"
+	assertions "github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mountConfigMap lays out files the way Kubernetes mounts ConfigMaps: files
+// are stored in timestamped directory, '..data' links to it and every key is
+// a symlink into '..data'.
+func mountConfigMap(t *testing.T, dir, version string, files map[string]string) {
+	dataDir := filepath.Join(dir, "..2024_01_01_"+version)
+	if err := os.Mkdir(dataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dataDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(dataDir), tmpLink); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, kubernetesDataDir)); err != nil {
+		t.Fatal(err)
+	}
+	for name := range files {
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Join(kubernetesDataDir, name), link); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConfig_WithDirectory(t *testing.T) {
+	assert := assertions.New(t)
+	dir := t.TempDir()
+	mountConfigMap(t, dir, "1", map[string]string{
+		"db.host":           "db.local\n",
+		"root.family1.key1": "overridden",
+		"service.yaml":      "name: api\nports:\n  - 80\n  - 443\n",
+	})
+
+	config := NewConfig("./test_config.yaml", Yaml, WithDirectory(dir, true))
+	assert.Equal("db.local", config.GetString("db.host"))
+	assert.Equal("overridden", config.GetString("root.family1.key1"))
+	assert.Equal("test121", config.GetString("root.family1.key2.subkey1"))
+	assert.Equal("api", config.GetString("service.name"))
+	assert.Equal(443, config.GetInt("service.ports[1]"))
+	assert.Equal(filepath.Join(dir, "db.host"), config.Explain("db.host").Source.Name)
+	assert.Equal(filepath.Join(dir, "service.yaml"), config.Explain("service.name").Source.Name)
+	assert.NotContains(config.Keys(""), kubernetesDataDir)
+
+	config = NewConfig("./test_config.yaml", Yaml, WithDirectory(dir, false))
+	assert.Equal("name: api\nports:\n  - 80\n  - 443", config.GetString("service.yaml"))
+}
+
+func TestConfig_WithDirectoryMissing(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on missing config directory")
+		}
+	}()
+	_ = NewConfig("./test_config.yaml", Yaml, WithDirectory("./missing", false))
+}
+
+func TestConfig_WatchReload(t *testing.T) {
+	assert := assertions.New(t)
+	dir := t.TempDir()
+	mountConfigMap(t, dir, "1", map[string]string{"db.host": "db-1"})
+
+	logger := &testLogger{}
+	config := NewConfig("./test_config.yaml", Yaml, WithDirectory(dir, false), WithLogger(logger))
+	stop := config.WatchReload(10 * time.Millisecond)
+	defer stop()
+	assert.Equal("db-1", config.GetString("db.host"))
+
+	mountConfigMap(t, dir, "2", map[string]string{"db.host": "db-2"})
+	assert.Eventually(func() bool {
+		return config.GetString("db.host") == "db-2"
+	}, time.Second, 10*time.Millisecond)
+
+	mountConfigMap(t, dir, "3", map[string]string{"db.host": "db-3", "broken.yaml": "a: [1"})
+	assert.NoError(config.Reload())
+	assert.Equal("db-3", config.GetString("db.host"))
+	assert.Equal("a: [1", config.GetString("broken.yaml"))
+}
+
+// flakyProvider fails the next load after failNext is set to 1.
+type flakyProvider struct {
+	failNext int32
+}
+
+func (p *flakyProvider) Load(context.Context) (map[string]interface{}, error) {
+	if atomic.CompareAndSwapInt32(&p.failNext, 1, 0) {
+		return nil, errors.New("temporarily unavailable")
+	}
+	return map[string]interface{}{"provided": "value"}, nil
+}
+
+func (p *flakyProvider) Watch(context.Context) <-chan Event {
+	return nil
+}
+
+func TestConfig_WatchReloadRetry(t *testing.T) {
+	assert := assertions.New(t)
+	dir := t.TempDir()
+	mountConfigMap(t, dir, "1", map[string]string{"db.host": "db-1"})
+
+	provider := &flakyProvider{}
+	config := NewConfig("./test_config.yaml", Yaml, WithDirectory(dir, false),
+		WithProviders(provider), WithLogger(&testLogger{}))
+	stop := config.WatchReload(10 * time.Millisecond)
+	defer stop()
+
+	atomic.StoreInt32(&provider.failNext, 1)
+	mountConfigMap(t, dir, "2", map[string]string{"db.host": "db-2"})
+	assert.Eventually(func() bool {
+		return config.GetString("db.host") == "db-2"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(int32(0), atomic.LoadInt32(&provider.failNext))
+}
+
+func TestConfig_ReloadError(t *testing.T) {
+	assert := assertions.New(t)
+	dir := t.TempDir()
+	mountConfigMap(t, dir, "1", map[string]string{"db.yaml": "host: db-1"})
+
+	config := NewConfig("./test_config.yaml", Yaml, WithDirectory(dir, true))
+	mountConfigMap(t, dir, "2", map[string]string{"db.yaml": "host: [db-2"})
+	assert.Error(config.Reload())
+	assert.Equal("db-1", config.GetString("db.host"))
+}
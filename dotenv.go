@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var dotenvKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+type dotenvVar struct {
+	value string
+	file  string
+	line  int
+}
+
+// WithDotenv reads variables from .env files and uses them as environment
+// variables when property is missing in config file. Real environment variables
+// take precedence over the variables of .env files, the process environment
+// is not modified. Variables of later files override the ones of earlier files.
+// Missing files are skipped, NewConfig panics if file can't be parsed.
+//
+// Lines have the form 'KEY=VALUE' with optional 'export ' prefix. Lines starting
+// with '#' are comments, unquoted values may be followed by inline comment
+// separated by whitespace. Values in double quotes may span multiple lines and
+// contain escape sequences \n, \r, \t, \", \\ and \$. Values in single quotes are
+// taken literally. References ${VAR} and ${VAR:-default} in unquoted and double
+// quoted values are expanded with environment variables and previously defined
+// variables.
+func WithDotenv(paths ...string) Option {
+	return func(c *Config) {
+		for _, path := range paths {
+			data, err := ioutil.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				log.Panicf("Failed to read dotenv file: %v", err)
+			}
+			if c.dotenv == nil {
+				c.dotenv = make(map[string]dotenvVar)
+			}
+			if err := parseDotenv(path, data, c.dotenv); err != nil {
+				log.Panicf("Failed to parse dotenv file %s: %v", path, err)
+			}
+		}
+	}
+}
+
+// lookupEnv returns value of the environment variable, falling back to the
+// variables read from .env files. Variables set to empty string are treated
+// as missing, the same way getters do.
+func (c *Config) lookupEnv(name string) (string, bool) {
+	envVar, ok := c.lookupEnvVar(name)
+	return envVar.value, ok
+}
+
+// lookupEnvVar returns the non-empty variable of process environment or of .env
+// file. Variables of process environment have no file.
+func (c *Config) lookupEnvVar(name string) (dotenvVar, bool) {
+	if val := os.Getenv(name); val != "" {
+		return dotenvVar{value: val}, true
+	}
+	if dotenv, ok := c.dotenv[name]; ok && dotenv.value != "" {
+		return dotenv, true
+	}
+	return dotenvVar{}, false
+}
+
+// getEnv returns value of the environment variable or empty string, see lookupEnv.
+func (c *Config) getEnv(name string) string {
+	val, _ := c.lookupEnv(name)
+	return val
+}
+
+func parseDotenv(path string, data []byte, vars map[string]dotenvVar) error {
+	lookup := func(name string) (string, bool) {
+		if val, ok := os.LookupEnv(name); ok {
+			return val, true
+		}
+		val, ok := vars[name]
+		return val.value, ok
+	}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		eqIdx := strings.Index(line, "=")
+		if eqIdx == -1 {
+			return fmt.Errorf("line %d: missing '='", number)
+		}
+		key := strings.TrimSpace(line[:eqIdx])
+		if !dotenvKeyRegexp.MatchString(key) {
+			return fmt.Errorf("line %d: invalid variable name %q", number, key)
+		}
+		raw := strings.TrimSpace(line[eqIdx+1:])
+
+		var val string
+		switch {
+		case strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'"):
+			quote := raw[0]
+			quoted := raw[1:]
+			closeIdx := findClosingQuote(quoted, quote)
+			for closeIdx == -1 && i+1 < len(lines) {
+				i++
+				quoted += "\n" + lines[i]
+				closeIdx = findClosingQuote(quoted, quote)
+			}
+			if closeIdx == -1 {
+				return fmt.Errorf("line %d: unterminated quoted value", number)
+			}
+			if quote == '\'' {
+				val = quoted[:closeIdx]
+			} else {
+				val = expandDotenv(unescapeDotenv(quoted[:closeIdx]), lookup)
+			}
+		default:
+			if commentIdx := strings.Index(raw, " #"); commentIdx != -1 {
+				raw = strings.TrimSpace(raw[:commentIdx])
+			}
+			val = expandDotenv(raw, lookup)
+		}
+		vars[key] = dotenvVar{value: val, file: path, line: number}
+	}
+	return nil
+}
+
+// findClosingQuote returns index of the quote not escaped with backslash
+// (escapes are recognized in double quotes only), -1 if there is no such quote.
+func findClosingQuote(str string, quote byte) int {
+	for i := 0; i < len(str); i++ {
+		if quote == '"' && str[i] == '\\' {
+			i++
+			continue
+		}
+		if str[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDotenv(str string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`, `\$`, "\x00")
+	return replacer.Replace(str)
+}
+
+// expandDotenv expands ${VAR} and ${VAR:-default} references. Escaped dollar
+// signs are marked with NUL character by unescapeDotenv and restored here.
+func expandDotenv(str string, lookup func(name string) (string, bool)) string {
+	var builder strings.Builder
+	for i := 0; i < len(str); {
+		if strings.HasPrefix(str[i:], referenceStart) {
+			if end := findClosingBrace(str, i+len(referenceStart)); end != -1 {
+				reference := str[i+len(referenceStart) : end]
+				name, defaultVal := reference, ""
+				if sepIdx := strings.Index(reference, defaultSeparator); sepIdx != -1 {
+					name, defaultVal = reference[:sepIdx], reference[sepIdx+len(defaultSeparator):]
+				}
+				if val, ok := lookup(name); ok && val != "" {
+					builder.WriteString(val)
+				} else {
+					builder.WriteString(expandDotenv(defaultVal, lookup))
+				}
+				i = end + 1
+				continue
+			}
+		}
+		builder.WriteByte(str[i])
+		i++
+	}
+	return strings.ReplaceAll(builder.String(), "\x00", "$")
+}
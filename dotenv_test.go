@@ -0,0 +1,71 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestConfig_Dotenv(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.yaml", Yaml, WithDotenv("./test_config.env", "./missing.env"))
+
+	assert.Equal("dotenv_val", config.GetString("root.family1.key19"))
+	assert.Equal("test11", config.GetString("root.family1.key1"))
+	assert.Equal("db.local", config.GetString("db.host"))
+	assert.Equal(5432, config.GetInt("db.port"))
+	assert.Equal("postgres://db.local:5432/app", config.GetString("db.url"))
+	assert.Equal("app", config.GetString("db.name"))
+	assert.Equal(`literal ${DB_HOST} \n`, config.GetString("single"))
+	assert.Equal("tab\there \"quoted\" ${DB_HOST}", config.GetString("escaped"))
+	assert.Equal("first line\nsecond line", config.GetString("multiline"))
+	assert.False(config.IsSet("empty"))
+	assert.Equal("default", config.GetString("empty", "default"))
+
+	_, found := os.LookupEnv("DB_HOST")
+	assert.False(found)
+}
+
+func TestConfig_DotenvPrecedence(t *testing.T) {
+	assert := assertions.New(t)
+
+	err := os.Setenv("DB_HOST", "db.env")
+	assert.Nil(err)
+
+	config := NewConfig("./test_config.yaml", Yaml, WithDotenv("./test_config.env", "./test_config_override.env"))
+	assert.Equal("db.env", config.GetString("db.host"))
+	assert.Equal("postgres://db.env:5432/app", config.GetString("db.url"))
+	assert.Equal(6432, config.GetInt("db.port"))
+
+	explanation := config.Explain("db.port")
+	assert.Equal("env DB_PORT from ./test_config_override.env:1", explanation.Source.String())
+	explanation = config.Explain("db.host")
+	assert.Equal("env DB_HOST", explanation.Source.String())
+
+	err = os.Setenv("DB_HOST", "")
+	assert.Nil(err)
+	assert.Equal("db.local", config.GetString("db.host"))
+	assert.Equal("env DB_HOST from ./test_config.env:3", config.Explain("db.host").Source.String())
+
+	err = os.Unsetenv("DB_HOST")
+	assert.Nil(err)
+}
+
+func TestParseDotenv_Errors(t *testing.T) {
+	assert := assertions.New(t)
+
+	vars := make(map[string]dotenvVar)
+	assert.EqualError(parseDotenv(".env", []byte("KEY"), vars), "line 1: missing '='")
+	assert.EqualError(parseDotenv(".env", []byte("\n1KEY=val"), vars), `line 2: invalid variable name "1KEY"`)
+	assert.EqualError(parseDotenv(".env", []byte("KEY=\"unterminated\nvalue"), vars), "line 1: unterminated quoted value")
+}
+
+func TestConfig_DotenvParsingErr(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on dotenv parsing error")
+		}
+	}()
+	_ = NewConfig("./test_config.yaml", Yaml, WithDotenv("./test_config.yaml"))
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// EnvVar documents the environment variable the property can be read from.
+type EnvVar struct {
+	// Key is the fully qualified dotted key of the property.
+	Key string
+	// Name is the name of the environment variable, see EnvVarName.
+	Name string
+	// Type is the type of the property: 'string', 'int', 'float', 'bool',
+	// 'secret', 'list', 'section', 'null' or, for fields of time.Duration type,
+	// 'duration'.
+	Type string
+	// Default is the value used if the variable is not set, empty for secrets.
+	Default string
+	// Description explains the property.
+	Description string
+	// InFile reports whether the property is set in config file. Config file
+	// takes precedence over env variables, so the variable is not read then.
+	InFile bool
+}
+
+// notOverridableNote marks variables of the properties set in config file.
+const notOverridableNote = "not overridable via env, set in config file"
+
+// EnvVars documents environment variables of all properties of config file and
+// of the keys declared with Expect or DescribeKey, sorted by key. Type is the
+// one declared with Expect or is derived from the value of config file, default
+// is the value of config file. Values of secrets are omitted, see Diff for the
+// way secrets are recognized. Description is set with DescribeKey. Properties
+// of config file are reported with InFile set, properties having no env
+// variable, e.g. keys with wildcards, are skipped.
+func (c *Config) EnvVars() []EnvVar {
+	c.mu.RLock()
+	types := make(map[string]string)
+	for _, exp := range c.expected {
+		types[c.normalizeKey(exp.key)] = typeNames[exp.valueType]
+	}
+	descriptions := make(map[string]string, len(c.descriptions))
+	for key, description := range c.descriptions {
+		descriptions[key] = description
+		if _, ok := types[key]; !ok {
+			types[key] = ""
+		}
+	}
+	c.mu.RUnlock()
+	secrets := c.secretKeys()
+
+	unique := make(map[string]bool)
+	for _, key := range c.AllKeys() {
+		unique[key] = true
+	}
+	for key := range types {
+		unique[key] = true
+	}
+	vars := make([]EnvVar, 0, len(unique))
+	for key := range unique {
+		name := envVarName(key)
+		if name == "" {
+			continue
+		}
+		raw, found := lookupProp(key, c.props())
+		envVar := EnvVar{Key: key, Name: name, Type: types[key], Description: descriptions[key], InFile: found}
+		if envVar.Type == "" {
+			envVar.Type = "string"
+			if found {
+				envVar.Type = valueTypeName(raw)
+			}
+		}
+		if isSecretKey(key, secrets) {
+			envVar.Type = typeNames[TypeSecret]
+		} else if found {
+			envVar.Default = envValue(raw)
+		}
+		vars = append(vars, envVar)
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Key < vars[j].Key
+	})
+	return vars
+}
+
+// StructEnvVars documents environment variables of the struct fields read by
+// Bind with the key, in order of fields. Types are named the same way as by
+// EnvVars, defaults and descriptions are taken from 'default' and 'description'
+// tags, e.g. `description:"Database host"`. Defaults of secrets are omitted.
+// Nested structs are documented with their fields, elements of slices of
+// structs are documented by the first element.
+func StructEnvVars(key string, target interface{}) ([]EnvVar, error) {
+	structType := reflect.TypeOf(target)
+	if structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType == nil || structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("target must be a struct or pointer to struct, got %T", target)
+	}
+	var vars []EnvVar
+	structEnvVars(key, structType, &vars)
+	return vars, nil
+}
+
+func structEnvVars(prefix string, structType reflect.Type, vars *[]EnvVar) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := fieldKey(field)
+		if name == "" {
+			continue
+		}
+		key := joinKey(prefix, name)
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			structEnvVars(key, field.Type, vars)
+			continue
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			structEnvVars(key+"[0]", field.Type.Elem(), vars)
+			continue
+		}
+		envVar := EnvVar{
+			Key:         key,
+			Name:        envVarName(key),
+			Type:        fieldTypeName(field.Type),
+			Default:     field.Tag.Get("default"),
+			Description: field.Tag.Get("description"),
+		}
+		if envVar.Name == "" {
+			continue
+		}
+		if isSecretKey(key, nil) {
+			envVar.Type, envVar.Default = typeNames[TypeSecret], ""
+		}
+		*vars = append(*vars, envVar)
+	}
+}
+
+// fieldTypeName returns type name of the struct field the way valueTypeName
+// names values of config file.
+func fieldTypeName(fieldType reflect.Type) string {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType == durationType {
+		return "duration"
+	}
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return typeNames[TypeInt]
+	case reflect.Float32, reflect.Float64:
+		return typeNames[TypeFloat]
+	case reflect.Bool:
+		return typeNames[TypeBool]
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map, reflect.Struct:
+		return "section"
+	default:
+		return typeNames[TypeString]
+	}
+}
+
+// EnvVarsMarkdown formats the variables as Markdown table with the columns
+// 'Variable', 'Property', 'Type', 'Default' and 'Description'. Descriptions of
+// the variables of properties set in config file note they are not read.
+func EnvVarsMarkdown(vars []EnvVar) string {
+	var builder strings.Builder
+	builder.WriteString("| Variable | Property | Type | Default | Description |\n")
+	builder.WriteString("|----------|----------|------|---------|-------------|\n")
+	for _, envVar := range vars {
+		description := envVar.Description
+		if envVar.InFile {
+			description = strings.TrimSpace(description + " (" + notOverridableNote + ")")
+		}
+		cells := []string{"`" + envVar.Name + "`", "`" + envVar.Key + "`", envVar.Type, envVar.Default, description}
+		for i, cell := range cells {
+			cells[i] = strings.ReplaceAll(strings.ReplaceAll(cell, "|", `\|`), "\n", "<br>")
+		}
+		if envVar.Default != "" {
+			cells[3] = "`" + cells[3] + "`"
+		}
+		builder.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return builder.String()
+}
+
+// EnvVarsTemplate formats the variables as sample .env file, which can be read
+// with WithDotenv. Every variable is preceded by comment with its description
+// and type, values are quoted if needed. Variables of properties set in config
+// file are commented out, as they are not read.
+func EnvVarsTemplate(vars []EnvVar) string {
+	var builder strings.Builder
+	for i, envVar := range vars {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		if envVar.Description != "" {
+			builder.WriteString("# " + strings.ReplaceAll(envVar.Description, "\n", "\n# ") + "\n")
+		}
+		builder.WriteString(fmt.Sprintf("# %s (%s)\n", envVar.Key, envVar.Type))
+		if envVar.InFile {
+			builder.WriteString("# " + notOverridableNote + "\n# ")
+		}
+		builder.WriteString(envVar.Name + "=" + quoteDotenv(envVar.Default) + "\n")
+	}
+	return builder.String()
+}
+
+// valueTypeName returns type name of the value of config file.
+func valueTypeName(val interface{}) string {
+	switch typed := val.(type) {
+	case float64:
+		if typed == float64(int64(typed)) {
+			return typeNames[TypeInt]
+		}
+		return typeNames[TypeFloat]
+	case bool:
+		return typeNames[TypeBool]
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "section"
+	case nil:
+		return "null"
+	default:
+		return typeNames[TypeString]
+	}
+}
+
+// envValue formats the value the way it's read from env variable: lists of
+// scalars are joined with commas as expected by Bind, other values as by FormatValue.
+func envValue(val interface{}) string {
+	if list, ok := val.([]interface{}); ok {
+		elems := make([]string, 0, len(list))
+		for _, elem := range list {
+			switch elem.(type) {
+			case []interface{}, map[string]interface{}:
+				return FormatValue(val)
+			}
+			elems = append(elems, FormatValue(elem))
+		}
+		return strings.Join(elems, ",")
+	}
+	if val == nil {
+		return ""
+	}
+	return FormatValue(val)
+}
+
+// quoteDotenv quotes the value of .env file if it contains whitespace or
+// characters having special meaning in .env files.
+func quoteDotenv(val string) string {
+	if !strings.ContainsAny(val, " \t\r\n#\"'$\\") {
+		return val
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(val) + `"`
+}
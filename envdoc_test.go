@@ -0,0 +1,93 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestConfig_EnvVars(t *testing.T) {
+	assert := assertions.New(t)
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "db:\n  host: localhost\n  port: 5432\n  password: secret\n" +
+			"ratio: 0.5\nhosts: [a, b]\ndebug: false\n",
+	})
+	config := NewConfig(dir+"/config.yaml", Yaml).
+		DescribeKey("db.host", "Database host").
+		DescribeKey("api.url", "API endpoint").
+		DescribeKey("servers[*].host", "Hosts of all servers").
+		Expect("db.port", TypeString)
+
+	vars := config.EnvVars()
+	assert.Equal([]EnvVar{
+		{Key: "api.url", Name: "API_URL", Type: "string", Description: "API endpoint"},
+		{Key: "db.host", Name: "DB_HOST", Type: "string", Default: "localhost", Description: "Database host", InFile: true},
+		{Key: "db.password", Name: "DB_PASSWORD", Type: "secret", InFile: true},
+		{Key: "db.port", Name: "DB_PORT", Type: "string", Default: "5432", InFile: true},
+		{Key: "debug", Name: "DEBUG", Type: "bool", Default: "false", InFile: true},
+		{Key: "hosts", Name: "HOSTS", Type: "list", Default: "a,b", InFile: true},
+		{Key: "ratio", Name: "RATIO", Type: "float", Default: "0.5", InFile: true},
+	}, vars)
+
+	assert.Contains(EnvVarsMarkdown(vars), "| `API_URL` | `api.url` | string |  | API endpoint |\n")
+	assert.Contains(EnvVarsMarkdown(vars),
+		"| `DB_HOST` | `db.host` | string | `localhost` | Database host (not overridable via env, set in config file) |\n")
+	template := EnvVarsTemplate(vars)
+	assert.Contains(template, "# api.url (string)\nAPI_URL=\n")
+	assert.Contains(template, "# db.host (string)\n# not overridable via env, set in config file\n# DB_HOST=localhost\n")
+	parsed := make(map[string]dotenvVar)
+	assert.NoError(parseDotenv(".env", []byte(template), parsed))
+	assert.Len(parsed, 1)
+}
+
+func TestStructEnvVars(t *testing.T) {
+	assert := assertions.New(t)
+	type server struct {
+		Host string `description:"Server host"`
+	}
+	type settings struct {
+		Name    string        `config:"name" default:"demo app" description:"Application name"`
+		Timeout time.Duration `default:"5s"`
+		Ratio   *float64
+		Tags    []string
+		Token   string `default:"changeme"`
+		Servers []server
+		Ignored string `config:"-"`
+		DB      struct {
+			Port int `default:"5432" description:"Port | number"`
+		} `config:"db"`
+	}
+
+	vars, err := StructEnvVars("app", &settings{})
+	assert.NoError(err)
+	assert.Equal([]EnvVar{
+		{Key: "app.name", Name: "APP_NAME", Type: "string", Default: "demo app", Description: "Application name"},
+		{Key: "app.timeout", Name: "APP_TIMEOUT", Type: "duration", Default: "5s"},
+		{Key: "app.ratio", Name: "APP_RATIO", Type: "float"},
+		{Key: "app.tags", Name: "APP_TAGS", Type: "list"},
+		{Key: "app.token", Name: "APP_TOKEN", Type: "secret"},
+		{Key: "app.servers[0].host", Name: "APP_SERVERS_0_HOST", Type: "string", Description: "Server host"},
+		{Key: "app.db.port", Name: "APP_DB_PORT", Type: "int", Default: "5432", Description: "Port | number"},
+	}, vars)
+
+	assert.Equal("| Variable | Property | Type | Default | Description |\n"+
+		"|----------|----------|------|---------|-------------|\n"+
+		"| `APP_NAME` | `app.name` | string | `demo app` | Application name |\n"+
+		"| `APP_TIMEOUT` | `app.timeout` | duration | `5s` |  |\n"+
+		"| `APP_RATIO` | `app.ratio` | float |  |  |\n"+
+		"| `APP_TAGS` | `app.tags` | list |  |  |\n"+
+		"| `APP_TOKEN` | `app.token` | secret |  |  |\n"+
+		"| `APP_SERVERS_0_HOST` | `app.servers[0].host` | string |  | Server host |\n"+
+		"| `APP_DB_PORT` | `app.db.port` | int | `5432` | Port \\| number |\n", EnvVarsMarkdown(vars))
+
+	template := EnvVarsTemplate(vars)
+	assert.Contains(template, "# Application name\n# app.name (string)\nAPP_NAME=\"demo app\"\n")
+	parsed := make(map[string]dotenvVar)
+	assert.NoError(parseDotenv(".env", []byte(template), parsed))
+	assert.Equal("demo app", parsed["APP_NAME"].value)
+	assert.Equal("5s", parsed["APP_TIMEOUT"].value)
+	assert.Equal("", parsed["APP_SERVERS_0_HOST"].value)
+
+	_, err = StructEnvVars("", "not a struct")
+	assert.EqualError(err, "target must be a struct or pointer to struct, got string")
+}
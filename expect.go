@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TypeString specifies expected type of property. To be used in Expect.
+	TypeString = iota
+	// TypeInt specifies expected type of property. To be used in Expect.
+	TypeInt
+	// TypeFloat specifies expected type of property. To be used in Expect.
+	TypeFloat
+	// TypeBool specifies expected type of property. To be used in Expect.
+	TypeBool
+	// TypeSecret specifies expected type of property: base64 encoded string. To be used in Expect.
+	TypeSecret
+)
+
+var typeNames = map[int]string{
+	TypeString: "string",
+	TypeInt:    "int",
+	TypeFloat:  "float",
+	TypeBool:   "bool",
+	TypeSecret: "secret",
+}
+
+type expectedKey struct {
+	key       string
+	valueType int
+}
+
+// KeyError describes invalid or missing property.
+type KeyError struct {
+	// Key is the property key.
+	Key string
+	// EnvVar is the name of env variable the property can be read from.
+	EnvVar string
+	// Value is the offending value, nil if property is missing.
+	Value interface{}
+	// Message describes the problem.
+	Message string
+}
+
+// ValidationError is returned from Validate and holds all problems found.
+type ValidationError []KeyError
+
+// Expect declares the property as required and having the value of the specified type.
+// Argument valueType is one of the constants: config.TypeString, config.TypeInt,
+// config.TypeFloat, config.TypeBool or config.TypeSecret.
+// Expectations are checked by Validate. The function returns the same Config,
+// so calls can be chained: c.Expect("db.host", TypeString).Expect("db.port", TypeInt).
+func (c *Config) Expect(key string, valueType int) *Config {
+	if _, ok := typeNames[valueType]; !ok {
+		log.Panicf("Unknown value type for key %s: %v", key, valueType)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expected = append(c.expected, expectedKey{key: key, valueType: valueType})
+	c.argsParsed = nil
+	return c
+}
+
+// Validate checks all properties declared with Expect. Each property must be
+// present either in config file or in environment variable and be convertible
+// to the declared type, required string properties must not be empty. It
+// returns ValidationError listing every problem found, including properties
+// failing to resolve like cyclic references, or nil if all expectations are met.
+func (c *Config) Validate() error {
+	c.mu.RLock()
+	expected := append([]expectedKey(nil), c.expected...)
+	c.mu.RUnlock()
+
+	var errs ValidationError
+	for _, exp := range expected {
+		if keyErr := c.validateKey(exp); keyErr != nil {
+			errs = append(errs, *keyErr)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateKey checks the single expectation, it returns nil if the property is valid.
+// Panic on reading the property is reported as the problem of this property.
+func (c *Config) validateKey(exp expectedKey) (keyErr *KeyError) {
+	keyErr = &KeyError{Key: exp.key, EnvVar: envVarName(exp.key)}
+	defer func() {
+		if r := recover(); r != nil {
+			keyErr.Message = fmt.Sprintf("failed to read property: %v", r)
+		}
+	}()
+	val := c.GetProp(exp.key)
+	if val == nil {
+		if env := c.readStringFromEnv(exp.key); env != "" {
+			val = env
+		}
+	}
+	if val == nil {
+		keyErr.Message = fmt.Sprintf("required %s property is missing", typeNames[exp.valueType])
+		return keyErr
+	}
+	if val == "" && (exp.valueType == TypeString || exp.valueType == TypeSecret) {
+		keyErr.Message = fmt.Sprintf("required %s property is empty", typeNames[exp.valueType])
+		return keyErr
+	}
+	if err := checkValueType(val, exp.valueType); err != nil {
+		keyErr.Value = val
+		keyErr.Message = err.Error()
+		return keyErr
+	}
+	return nil
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, keyErr := range e {
+		messages = append(messages, keyErr.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// String formats the error in human-readable form.
+func (e KeyError) String() string {
+	msg := fmt.Sprintf("%s (env %s): %s", e.Key, e.EnvVar, e.Message)
+	if e.Value != nil {
+		msg += fmt.Sprintf(", got %v", e.Value)
+	}
+	return msg
+}
+
+// checkValueType reports whether the value read from file or env variable can
+// be converted to the type by the corresponding getter.
+func checkValueType(val interface{}, valueType int) error {
+	switch valueType {
+	case TypeInt:
+		switch typed := val.(type) {
+		case float64:
+			if typed == float64(int(typed)) {
+				return nil
+			}
+		case string:
+			if _, err := strconv.Atoi(typed); err == nil {
+				return nil
+			}
+		}
+	case TypeFloat:
+		switch typed := val.(type) {
+		case float64:
+			return nil
+		case string:
+			if _, err := strconv.ParseFloat(typed, 64); err == nil {
+				return nil
+			}
+		}
+	case TypeBool:
+		switch typed := val.(type) {
+		case bool:
+			return nil
+		case string:
+			if strings.EqualFold(typed, "true") || strings.EqualFold(typed, "false") {
+				return nil
+			}
+		}
+	case TypeSecret:
+		if strVal, ok := val.(string); ok {
+			if _, err := base64.StdEncoding.DecodeString(strVal); err == nil {
+				return nil
+			}
+			return fmt.Errorf("value is not base64 encoded")
+		}
+	default:
+		switch val.(type) {
+		case map[string]interface{}, []interface{}:
+		default:
+			return nil
+		}
+	}
+	return fmt.Errorf("value is not convertible to %s", typeNames[valueType])
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.yaml", Yaml).
+		Expect("root.family1.key1", TypeString).
+		Expect("root.family1.key2.subkey2", TypeInt).
+		Expect("root.family1.key2.subkey2", TypeFloat).
+		Expect("subroot.family1.key2", TypeFloat).
+		Expect("root.family3.key2", TypeBool).
+		Expect("subroot.family1.key3.secret", TypeSecret).
+		Expect("test.expect.port", TypeInt)
+
+	err := os.Setenv("TEST_EXPECT_PORT", "8080")
+	assert.Nil(err)
+	assert.Nil(config.Validate())
+	err = os.Unsetenv("TEST_EXPECT_PORT")
+	assert.Nil(err)
+}
+
+func TestConfig_ValidateErrors(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.yaml", Yaml).
+		Expect("root.family1.key1", TypeInt).
+		Expect("subroot.family1.key2", TypeInt).
+		Expect("root.family1.key1", TypeBool).
+		Expect("root.family1.key1", TypeSecret).
+		Expect("root.family1", TypeString).
+		Expect("test.expect.port", TypeInt).
+		Expect("test.expect.ratio", TypeFloat).
+		Expect("test.expect.enabled", TypeBool)
+
+	err := os.Setenv("TEST_EXPECT_RATIO", "high")
+	assert.Nil(err)
+	err = os.Setenv("TEST_EXPECT_ENABLED", "yes")
+	assert.Nil(err)
+
+	err = config.Validate()
+	assert.Equal(ValidationError{
+		{Key: "root.family1.key1", EnvVar: "ROOT_FAMILY1_KEY1", Value: "test11", Message: "value is not convertible to int"},
+		{Key: "subroot.family1.key2", EnvVar: "SUBROOT_FAMILY1_KEY2", Value: 212.212, Message: "value is not convertible to int"},
+		{Key: "root.family1.key1", EnvVar: "ROOT_FAMILY1_KEY1", Value: "test11", Message: "value is not convertible to bool"},
+		{Key: "root.family1.key1", EnvVar: "ROOT_FAMILY1_KEY1", Value: "test11", Message: "value is not base64 encoded"},
+		{Key: "root.family1", EnvVar: "ROOT_FAMILY1", Message: "required string property is missing"},
+		{Key: "test.expect.port", EnvVar: "TEST_EXPECT_PORT", Message: "required int property is missing"},
+		{Key: "test.expect.ratio", EnvVar: "TEST_EXPECT_RATIO", Value: "high", Message: "value is not convertible to float"},
+		{Key: "test.expect.enabled", EnvVar: "TEST_EXPECT_ENABLED", Value: "yes", Message: "value is not convertible to bool"},
+	}, err)
+	assert.Contains(err.Error(), "test.expect.port (env TEST_EXPECT_PORT): required int property is missing; ")
+
+	err = os.Unsetenv("TEST_EXPECT_RATIO")
+	assert.Nil(err)
+	err = os.Unsetenv("TEST_EXPECT_ENABLED")
+	assert.Nil(err)
+}
+
+func TestConfig_ExpectUnknownType(t *testing.T) {
+	config := NewConfig("./test_config.yaml", Yaml)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on unknown type")
+		}
+	}()
+	config.Expect("root.family1.key1", 42)
+}
+
+func TestConfig_ValidateUnresolvable(t *testing.T) {
+	assert := assertions.New(t)
+
+	path := writeTempFile(t, "config.yaml", "name: ''\nsecret: ''\n")
+	config := NewConfig(path, Yaml).
+		Expect("name", TypeString).
+		Expect("secret", TypeSecret)
+	assert.Equal(ValidationError{
+		{Key: "name", EnvVar: "NAME", Message: "required string property is empty"},
+		{Key: "secret", EnvVar: "SECRET", Message: "required secret property is empty"},
+	}, config.Validate())
+
+	config = NewConfig("./test_config_interpolation.yaml", Yaml).
+		Expect("cycle.a", TypeString).
+		Expect("base.port", TypeInt)
+	assert.Equal(ValidationError{
+		{Key: "cycle.a", EnvVar: "CYCLE_A",
+			Message: "failed to read property: Cyclic reference in property cycle.a: cycle.a -> cycle.b -> cycle.c -> cycle.a"},
+	}, config.Validate())
+}
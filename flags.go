@@ -0,0 +1,229 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithFlags makes properties of the command-line flags set in the FlagSet take
+// precedence over config file and environment variables. Flag names are property
+// keys, e.g. '--root.family1.key1=x' overrides property 'root.family1.key1'.
+// Only flags explicitly set on command line are used, so the FlagSet may be
+// parsed after NewConfig. See also RegisterFlags.
+func WithFlags(fs *flag.FlagSet) Option {
+	return func(c *Config) {
+		c.flagSets = append(c.flagSets, fs)
+	}
+}
+
+// WithArgs makes properties passed as command-line arguments '--key=value' take
+// precedence over config file and environment variables. Unlike WithFlags it
+// accepts arbitrary keys. Arguments may have one or two leading dashes, flags
+// without value are set to 'true'. The value may follow as a separate argument
+// ('--key value') only for keys declared with DescribeKey, DeclareKnownKeys or
+// Expect and not expected to be config.TypeBool, otherwise the next argument is
+// positional. Arguments not starting with dash and negative numbers are skipped,
+// '--' ends parsing. Later arguments override earlier ones. NewConfig panics on
+// malformed arguments.
+func WithArgs(args []string) Option {
+	return func(c *Config) {
+		if _, err := parseArgs(args, func(string) bool { return false }); err != nil {
+			log.Panicf("Failed to parse command-line arguments: %v", err)
+		}
+		c.args = append(c.args, args...)
+	}
+}
+
+// DescribeKey declares the property as known and sets its description, which
+// is used as usage text of the flag created by RegisterFlags.
+func (c *Config) DescribeKey(key, description string) *Config {
+	c.DeclareKnownKeys(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.descriptions == nil {
+		c.descriptions = make(map[string]string)
+	}
+	c.descriptions[c.normalizeKey(key)] = description
+	return c
+}
+
+// RegisterFlags defines a flag in the FlagSet for every property declared with
+// DescribeKey, DeclareKnownKeys or Expect, unless the FlagSet already has the
+// flag, and makes the FlagSet a source of properties as WithFlags does.
+// Usage text of the flag is the description of the key followed by the name of
+// its environment variable and the source the value is currently resolved from,
+// e.g. 'file ./config.yaml:3'. Defaults of the flags are the values currently
+// resolved, except for secrets recognized the same way as by Diff, which have
+// empty defaults, so they are not printed with usage. Defaults don't change
+// the precedence, only the flags set on command line override other sources.
+// Properties expected to be config.TypeBool get boolean flags, which can be set
+// without value.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	c.mu.RLock()
+	types := make(map[string]int)
+	for _, exp := range c.expected {
+		types[c.normalizeKey(exp.key)] = exp.valueType
+	}
+	keys := make([]string, 0, len(c.declared)+len(types))
+	for key := range c.declared {
+		keys = append(keys, key)
+	}
+	for key := range types {
+		if !c.declared[key] {
+			keys = append(keys, key)
+		}
+	}
+	descriptions := make(map[string]string, len(c.descriptions))
+	for key, description := range c.descriptions {
+		descriptions[key] = description
+	}
+	c.mu.RUnlock()
+	sort.Strings(keys)
+	secrets := c.secretKeys()
+
+	for _, key := range keys {
+		if fs.Lookup(key) != nil {
+			continue
+		}
+		usage := fmt.Sprintf("(env %s)", envVarName(key))
+		var defaultVal interface{}
+		if explanation := c.Explain(key); explanation.Source != nil {
+			usage = fmt.Sprintf("(env %s, set in %s)", envVarName(key), explanation.Source)
+			if !isSecretKey(key, secrets) {
+				defaultVal = explanation.Value
+			}
+		}
+		if description := descriptions[key]; description != "" {
+			usage = description + " " + usage
+		}
+		if valueType, ok := types[key]; ok && valueType == TypeBool {
+			defaultBool, _ := strconv.ParseBool(fmt.Sprint(defaultVal))
+			fs.Bool(key, defaultBool, usage)
+		} else if defaultVal != nil {
+			fs.String(key, FormatValue(defaultVal), usage)
+		} else {
+			fs.String(key, "", usage)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, bound := range c.flagSets {
+		if bound == fs {
+			return
+		}
+	}
+	c.flagSets = append(c.flagSets, fs)
+}
+
+// lookupFlag returns value of the flag set on command line for the key.
+// The key is expected to be normalized already. Flags of the FlagSet are read
+// once after it is parsed.
+func (c *Config) lookupFlag(key string) (string, bool) {
+	c.mu.RLock()
+	flagSets := c.flagSets
+	c.mu.RUnlock()
+	for _, fs := range flagSets {
+		if val, found := c.setFlags(fs)[key]; found {
+			return val, true
+		}
+	}
+	val, found := c.argValues()[key]
+	return val, found
+}
+
+// setFlags returns values of the flags set on command line by normalized names.
+// Nothing is set until the FlagSet is parsed.
+func (c *Config) setFlags(fs *flag.FlagSet) map[string]string {
+	if !fs.Parsed() {
+		return nil
+	}
+	if cached, ok := c.flagValues.Load(fs); ok {
+		return cached.(map[string]string)
+	}
+	values := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		values[c.normalizeKey(f.Name)] = f.Value.String()
+	})
+	c.flagValues.Store(fs, values)
+	return values
+}
+
+// argValues returns values of the arguments passed with WithArgs by normalized
+// keys. Arguments are parsed again after new keys are declared, since the value
+// of declared key may follow as a separate argument.
+func (c *Config) argValues() map[string]string {
+	c.mu.RLock()
+	values := c.argsParsed
+	c.mu.RUnlock()
+	if values != nil || len(c.args) == 0 {
+		return values
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	types := make(map[string]int)
+	for _, exp := range c.expected {
+		types[c.normalizeKey(exp.key)] = exp.valueType
+	}
+	takesValue := func(name string) bool {
+		key := c.normalizeKey(name)
+		valueType, expected := types[key]
+		return (c.declared[key] || expected) && valueType != TypeBool
+	}
+	// syntax of the arguments is checked by WithArgs
+	parsed, _ := parseArgs(c.args, takesValue)
+	values = make(map[string]string, len(parsed))
+	for _, arg := range parsed {
+		values[c.normalizeKey(arg.key)] = arg.value
+	}
+	c.argsParsed = values
+	return values
+}
+
+type argument struct {
+	key   string
+	value string
+}
+
+// parseArgs parses '--key=value', '--key value' and '--key' arguments. The next
+// argument is taken as the value only if takesValue reports true for the name.
+func parseArgs(args []string, takesValue func(name string) bool) ([]argument, error) {
+	var parsed []argument
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !isFlagArg(arg) {
+			continue
+		}
+		nameVal := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		name, val := nameVal, "true"
+		if eqIdx := strings.Index(nameVal, "="); eqIdx != -1 {
+			name, val = nameVal[:eqIdx], nameVal[eqIdx+1:]
+		} else if i+1 < len(args) && !isFlagArg(args[i+1]) && args[i+1] != "--" && takesValue(name) {
+			i++
+			val = args[i]
+		}
+		if name == "" || strings.HasPrefix(name, "-") {
+			return nil, fmt.Errorf("malformed argument %q", arg)
+		}
+		parsed = append(parsed, argument{key: name, value: val})
+	}
+	return parsed, nil
+}
+
+// isFlagArg reports whether the argument is a flag rather than positional
+// argument or negative number like '-5'.
+func isFlagArg(arg string) bool {
+	if !strings.HasPrefix(arg, "-") || arg == "-" {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
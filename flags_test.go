@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	assertions "github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestConfig_WithFlags(t *testing.T) {
+	assert := assertions.New(t)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("root.family1.key1", "flag-default", "")
+	fs.String("root.family2", "", "")
+	fs.Int("new.port", 0, "")
+
+	config := NewConfig("./test_config.yaml", Yaml, WithFlags(fs))
+	assert.Equal("test11", config.GetString("root.family1.key1"))
+
+	assert.NoError(fs.Parse([]string{"--root.family1.key1=from-flag", "-new.port", "9090"}))
+	assert.Equal("from-flag", config.GetString("root.family1.key1"))
+	assert.Equal(9090, config.GetInt("new.port"))
+	assert.Equal("test2", config.GetString("root.family2"))
+	assert.True(config.IsSet("new.port"))
+
+	explanation := config.Explain("root.family1.key1")
+	assert.Equal("flag --root.family1.key1", explanation.Source.String())
+	assert.Equal("test11", explanation.Shadowed[0].Value)
+}
+
+func TestConfig_WithArgs(t *testing.T) {
+	assert := assertions.New(t)
+	assert.NoError(os.Setenv("ARGS_ENV_KEY", "from-env"))
+	defer os.Unsetenv("ARGS_ENV_KEY")
+
+	config := NewConfig("./test_config.yaml", Yaml, WithArgs([]string{
+		"serve", "--root.family1.key1=first", "--args.env.key=from-args", "--verbose",
+		"-root.family1.key1=second", "--", "--ignored=true",
+	}))
+	assert.Equal("second", config.GetString("root.family1.key1"))
+	assert.Equal("from-args", config.GetString("args.env.key"))
+	assert.True(config.GetBool("verbose"))
+	assert.False(config.IsSet("ignored"))
+	assert.False(config.IsSet("serve"))
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on malformed argument")
+		}
+	}()
+	_ = NewConfig("./test_config.yaml", Yaml, WithArgs([]string{"--=value"}))
+}
+
+func TestConfig_RegisterFlags(t *testing.T) {
+	assert := assertions.New(t)
+	config := NewConfig("./test_config.yaml", Yaml)
+	config.DescribeKey("root.family1.key1", "First key").
+		DeclareKnownKeys("db.host").
+		Expect("root.family3.key1", TypeBool)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("db.host", "predefined", "")
+	config.RegisterFlags(fs)
+
+	key1 := fs.Lookup("root.family1.key1")
+	assert.Equal("First key (env ROOT_FAMILY1_KEY1, set in file ./test_config.yaml:3)", key1.Usage)
+	assert.Equal("test11", key1.DefValue)
+	assert.Equal("predefined", fs.Lookup("db.host").DefValue)
+	assert.Equal("true", fs.Lookup("root.family3.key1").DefValue)
+	assert.True(config.GetBool("root.family3.key1"))
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	assert.Contains(usage.String(), "-root.family1.key1 string")
+	assert.Contains(usage.String(), `(default "test11")`)
+
+	assert.NoError(fs.Parse([]string{"--root.family3.key1=false", "--root.family1.key1", "x"}))
+	assert.False(config.GetBool("root.family3.key1"))
+	assert.Equal("x", config.GetString("root.family1.key1"))
+}
+
+func TestConfig_RegisterFlagsSecrets(t *testing.T) {
+	assert := assertions.New(t)
+	assert.NoError(os.Setenv("FLAGS_DB_PASSWORD", "hunter2"))
+	defer os.Unsetenv("FLAGS_DB_PASSWORD")
+
+	config := NewConfig("./test_config.yaml", Yaml)
+	config.DescribeKey("flags.db.password", "Database password").DeclareKnownKeys("flags.db.user").
+		Expect("subroot.family1.key1", TypeSecret)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.RegisterFlags(fs)
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	assert.NotContains(usage.String(), "hunter2")
+	assert.NotContains(usage.String(), "211")
+	assert.Equal("", fs.Lookup("flags.db.password").DefValue)
+	assert.Equal("", fs.Lookup("subroot.family1.key1").DefValue)
+	assert.Equal("Database password (env FLAGS_DB_PASSWORD, set in env FLAGS_DB_PASSWORD)",
+		fs.Lookup("flags.db.password").Usage)
+	assert.Equal("(env FLAGS_DB_USER)", fs.Lookup("flags.db.user").Usage)
+
+	assert.NoError(fs.Parse(nil))
+	assert.Equal("hunter2", config.GetString("flags.db.password"))
+}
+
+func TestConfig_WithArgsValues(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.yaml", Yaml, WithArgs([]string{
+		"--verbose", "serve", "--offset", "-5", "--port", "8080", "-3", "--debug", "--",
+	}))
+	assert.True(config.GetBool("verbose"))
+	assert.Equal("true", config.GetString("port"))
+
+	config.DeclareKnownKeys("offset", "port").Expect("debug", TypeBool)
+	assert.Equal(-5, config.GetInt("offset"))
+	assert.Equal(8080, config.GetInt("port"))
+	assert.True(config.GetBool("debug"))
+	assert.False(config.IsSet("serve"))
+	assert.False(config.IsSet("3"))
+}
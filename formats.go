@@ -0,0 +1,326 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/BurntSushi/toml"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+)
+
+// Format parses config files of some format into the properties tree.
+// Custom formats are plugged in with RegisterFormat.
+type Format interface {
+	// Name returns short name of the format, e.g. 'yaml'.
+	Name() string
+	// Extensions returns file extensions of the format including the dot, e.g. '.yaml'.
+	Extensions() []string
+	// Parse parses the document into the properties tree. Nested sections are
+	// represented with map[string]interface{}, lists with []interface{}.
+	Parse(data []byte) (map[string]interface{}, error)
+}
+
+// FormatMarshaler is implemented by formats that can serialize the properties tree.
+// Built-in formats Yaml, Json, Toml and Properties implement it.
+type FormatMarshaler interface {
+	Marshal(props map[string]interface{}) ([]byte, error)
+}
+
+var (
+	formatsMu    sync.RWMutex
+	formats      = make(map[int]Format)
+	nextFormatID = Json5 + 1
+	formatByExt  = make(map[string]int)
+	formatByName = make(map[string]int)
+)
+
+func init() {
+	registerFormat(Yaml, &converterFormat{name: "yaml", extensions: []string{".yaml", ".yml"},
+		toJSON: yamlToJSON, marshal: marshalYaml})
+	registerFormat(Json, &converterFormat{name: "json", extensions: []string{".json"},
+		toJSON: func(data []byte) ([]byte, error) { return data, nil }, marshal: marshalJSON})
+	registerFormat(Toml, &converterFormat{name: "toml", extensions: []string{".toml"},
+		toJSON: tomlToJSON, marshal: marshalToml})
+	registerFormat(Properties, &converterFormat{name: "properties", extensions: []string{".properties"},
+		toJSON: propertiesToJSON, marshal: marshalProperties})
+	registerFormat(Ini, &converterFormat{name: "ini", extensions: []string{".ini"},
+		toJSON: iniToJSON})
+	registerFormat(Jsonc, &converterFormat{name: "jsonc", extensions: []string{".jsonc"},
+		toJSON: json5ToJSON})
+	registerFormat(Json5, &converterFormat{name: "json5", extensions: []string{".json5"},
+		toJSON: json5ToJSON})
+}
+
+// RegisterFormat makes the format available to NewConfig and to extension
+// detection with config.Auto. It returns the value to be passed to NewConfig
+// as the format argument. Formats registered later take precedence for the
+// same extension or name.
+//
+// Built-in formats and their extensions: config.Yaml (.yaml, .yml), config.Json
+// (.json), config.Toml (.toml), config.Properties (.properties), config.Ini (.ini),
+// config.Jsonc (.jsonc) and config.Json5 (.json5).
+func RegisterFormat(format Format) int {
+	formatsMu.Lock()
+	id := nextFormatID
+	nextFormatID++
+	formatsMu.Unlock()
+	registerFormat(id, format)
+	return id
+}
+
+// registerFormat registers the format with the id, built-in formats are
+// registered with the values of the format constants.
+func registerFormat(id int, format Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[id] = format
+	formatByName[strings.ToLower(format.Name())] = id
+	for _, ext := range format.Extensions() {
+		formatByExt[strings.ToLower(ext)] = id
+	}
+}
+
+// FormatByName returns the format value registered with the name, e.g. 'yaml'.
+func FormatByName(name string) (int, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	id, ok := formatByName[strings.ToLower(name)]
+	return id, ok
+}
+
+// FormatByExtension returns the format value registered for extension of the file.
+func FormatByExtension(filePath string) (int, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	id, ok := formatByExt[strings.ToLower(filepath.Ext(filePath))]
+	return id, ok
+}
+
+// Marshal serializes properties read from file into the format. Format must
+// implement FormatMarshaler. References in values are not expanded.
+func (c *Config) Marshal(format int) ([]byte, error) {
+	f := getFormat(format)
+	if f == nil {
+		return nil, fmt.Errorf("unknown config format: %v", format)
+	}
+	marshaler, ok := f.(FormatMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("format %s doesn't support serialization", f.Name())
+	}
+	return marshaler.Marshal(c.props())
+}
+
+func getFormat(format int) Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	return formats[format]
+}
+
+// resolveFormat detects format by file extension for config.Auto and checks
+// that the format is registered. It panics for unknown formats.
+func resolveFormat(format int, filePath string) int {
+	if format == Auto {
+		detected, ok := FormatByExtension(filePath)
+		if !ok {
+			log.Panicf("Unknown config file extension: %s (allowed values %s)", filePath, registeredExtensions())
+		}
+		return detected
+	}
+	if getFormat(format) == nil {
+		log.Panicf("Unknown config format: %v (allowed values config.Yaml, config.Json, config.Toml, "+
+			"config.Properties, config.Ini, config.Jsonc, config.Json5 or registered with RegisterFormat)", format)
+	}
+	return format
+}
+
+// parseWithFormat parses the document and normalizes the resulting tree, so
+// values of custom formats have the same types as values decoded from JSON.
+func parseWithFormat(format int, data []byte) (map[string]interface{}, error) {
+	f := getFormat(format)
+	props, err := f.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	normalized, err := normalizeProps(props)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	return normalized, nil
+}
+
+// normalizeProps converts values of the tree to the types decoded from JSON.
+func normalizeProps(props map[string]interface{}) (map[string]interface{}, error) {
+	plane, err := json.Marshal(props)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(plane, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// parseIndexed parses YAML or JSON document into the properties tree and the
+// node tree for line indexing, see parseWithFormat. YAML document is parsed
+// once. JSON document is parsed with encoding/json, the node tree is read on
+// the best effort basis, as not every JSON document is valid YAML, e.g. the
+// one with '\/' escapes; line numbers stay unknown then.
+func parseIndexed(format int, data []byte) (map[string]interface{}, *yamlv3.Node, error) {
+	if format == Json {
+		props, err := parseWithFormat(Json, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		root, err := parseYamlNode(data)
+		if err != nil {
+			root = nil
+		}
+		return props, root, nil
+	}
+	root, err := parseYamlNode(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("yaml: %w", err)
+	}
+	props, err := yamlNodeToProps(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("yaml: %w", err)
+	}
+	normalized, err := normalizeProps(props)
+	if err != nil {
+		return nil, nil, fmt.Errorf("yaml: %w", err)
+	}
+	return normalized, root, nil
+}
+
+func registeredExtensions() string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	extensions := make([]string, 0, len(formatByExt))
+	for ext := range formatByExt {
+		extensions = append(extensions, ext)
+	}
+	sort.Strings(extensions)
+	return strings.Join(extensions, ", ")
+}
+
+// converterFormat is built-in format converting documents to JSON.
+type converterFormat struct {
+	name       string
+	extensions []string
+	toJSON     func(data []byte) ([]byte, error)
+	marshal    func(props map[string]interface{}) ([]byte, error)
+}
+
+func (f *converterFormat) Name() string {
+	return f.name
+}
+
+func (f *converterFormat) Extensions() []string {
+	return f.extensions
+}
+
+func (f *converterFormat) Parse(data []byte) (map[string]interface{}, error) {
+	plane, err := f.toJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	var props map[string]interface{}
+	if err := json.Unmarshal(plane, &props); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+func (f *converterFormat) Marshal(props map[string]interface{}) ([]byte, error) {
+	if f.marshal == nil {
+		return nil, fmt.Errorf("format %s doesn't support serialization", f.name)
+	}
+	return f.marshal(props)
+}
+
+func marshalYaml(props map[string]interface{}) ([]byte, error) {
+	return yaml.Marshal(props)
+}
+
+func marshalJSON(props map[string]interface{}) ([]byte, error) {
+	return json.MarshalIndent(props, "", "  ")
+}
+
+func marshalToml(props map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(props); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalProperties writes every property as flat 'key=value' line sorted by key.
+// Lists are written as JSON arrays.
+func marshalProperties(props map[string]interface{}) ([]byte, error) {
+	var keys []string
+	values := make(map[string]interface{})
+	walkProps("", props, func(key string, val interface{}) {
+		keys = append(keys, key)
+		values[key] = val
+	})
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for _, key := range keys {
+		var strVal string
+		switch val := values[key].(type) {
+		case nil:
+		case []interface{}, map[string]interface{}:
+			encoded, err := json.Marshal(val)
+			if err != nil {
+				return nil, err
+			}
+			strVal = string(encoded)
+		default:
+			strVal = fmt.Sprintf("%v", val)
+		}
+		buf.WriteString(escapeProperties(key, true) + "=" + escapeProperties(strVal, false) + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeProperties escapes special characters of .properties key or value.
+func escapeProperties(str string, isKey bool) string {
+	var builder strings.Builder
+	for i, r := range str {
+		switch {
+		case r == '\\':
+			builder.WriteString(`\\`)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r == '\f':
+			builder.WriteString(`\f`)
+		case r == '=' || r == ':' || r == '#' || r == '!':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r == ' ' && (isKey || i == 0):
+			builder.WriteString(`\ `)
+		case r > 0xffff:
+			// runes outside of Basic Multilingual Plane are written as UTF-16 surrogate pair
+			high, low := utf16.EncodeRune(r)
+			builder.WriteString(fmt.Sprintf(`\u%04x\u%04x`, high, low))
+		case r > 0x7e || r < 0x20:
+			builder.WriteString(fmt.Sprintf(`\u%04x`, r))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
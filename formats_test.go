@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	assertions "github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lineFormat is test format with 'key value' lines.
+type lineFormat struct{}
+
+func (lineFormat) Name() string {
+	return "lines"
+}
+
+func (lineFormat) Extensions() []string {
+	return []string{".lines"}
+}
+
+func (lineFormat) Parse(data []byte) (map[string]interface{}, error) {
+	props := make(map[string]interface{})
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed line %q", line)
+		}
+		setNestedProp(props, fields[0], fields[1])
+	}
+	return props, nil
+}
+
+func writeTempFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRegisterFormat(t *testing.T) {
+	assert := assertions.New(t)
+
+	format := RegisterFormat(lineFormat{})
+	assert.Greater(format, Json5)
+	byName, ok := FormatByName("LINES")
+	assert.True(ok)
+	assert.Equal(format, byName)
+
+	path := writeTempFile(t, "config.lines", "app.name demo\napp.port 8080\n")
+	config := NewConfig(path, format)
+	assert.Equal("demo", config.GetString("app.name"))
+	assert.Equal(8080, config.GetInt("app.port"))
+
+	config = NewConfig(path, Auto)
+	assert.Equal("demo", config.GetString("app.name"))
+
+	_, err := config.Marshal(format)
+	assert.EqualError(err, "format lines doesn't support serialization")
+
+	defer func() {
+		r := recover()
+		assert.NotNil(r)
+		assert.Contains(fmt.Sprint(r), `lines: malformed line "broken"`)
+	}()
+	_ = NewConfig(writeTempFile(t, "broken.lines", "broken"), format)
+}
+
+func TestFormatByExtension(t *testing.T) {
+	assert := assertions.New(t)
+
+	for path, expected := range map[string]int{
+		"./config.yaml":       Yaml,
+		"./config.YML":        Yaml,
+		"./config.json":       Json,
+		"./config.toml":       Toml,
+		"./config.properties": Properties,
+		"./config.ini":        Ini,
+		"./config.jsonc":      Jsonc,
+		"./config.json5":      Json5,
+	} {
+		format, ok := FormatByExtension(path)
+		assert.True(ok, path)
+		assert.Equal(expected, format, path)
+	}
+	_, ok := FormatByExtension("./config.xml")
+	assert.False(ok)
+}
+
+func TestConfig_AutoFormat(t *testing.T) {
+	for _, path := range []string{"./test_config.yaml", "./test_config.json", "./test_config.toml", "./test_config.json5"} {
+		testConfigPositiveCases(t, NewConfig(path, Auto))
+	}
+}
+
+func TestConfig_AutoFormatUnknownExtension(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on unknown file extension")
+		}
+	}()
+	_ = NewConfig("./LICENSE", Auto)
+}
+
+func TestConfig_Marshal(t *testing.T) {
+	assert := assertions.New(t)
+	config := NewConfig("./test_config.yaml", Yaml)
+
+	for format, ext := range map[int]string{Yaml: "yaml", Json: "json", Toml: "toml", Properties: "properties"} {
+		data, err := config.Marshal(format)
+		assert.NoError(err)
+		converted := NewConfig(writeTempFile(t, "config."+ext, string(data)), format)
+		assert.Equal(config.AllKeys(), converted.AllKeys(), ext)
+		assert.Equal(config.GetString("root.family1.key1"), converted.GetString("root.family1.key1"), ext)
+	}
+
+	_, err := config.Marshal(Ini)
+	assert.EqualError(err, "format ini doesn't support serialization")
+	_, err = config.Marshal(99)
+	assert.EqualError(err, "unknown config format: 99")
+}
+
+func TestFormatByName_BuiltIn(t *testing.T) {
+	assert := assertions.New(t)
+
+	for name, expected := range map[string]int{
+		"yaml": Yaml, "json": Json, "toml": Toml, "properties": Properties,
+		"ini": Ini, "jsonc": Jsonc, "json5": Json5,
+	} {
+		format, ok := FormatByName(name)
+		assert.True(ok)
+		assert.Equal(expected, format, name)
+	}
+}
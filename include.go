@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// includeKey is the key of include directive, see resolveIncludes. The dollar
+// sign keeps ordinary properties named 'include' from being treated as directives.
+const includeKey = "$include"
+
+// plainIncludeKey is the key of include directive enabled with WithPlainIncludes.
+const plainIncludeKey = "include"
+
+// WithPlainIncludes makes NewConfig recognize 'include' directives in addition
+// to '$include' ones, e.g. 'include: base.yaml'. It's opt-in, as without it
+// properties named 'include' are ordinary properties.
+func WithPlainIncludes() Option {
+	return func(c *Config) {
+		c.plainIncludes = true
+	}
+}
+
+// includeResolver resolves include directives, see resolveIncludes.
+type includeResolver struct {
+	// keys are the keys of include directives in order they are resolved.
+	keys []string
+	// files stores paths of the files of included properties by their keys.
+	files map[string]string
+	// patterns collects paths and glob patterns of all included files
+	// relative to the working directory, they are watched by WatchReload.
+	patterns []string
+}
+
+// newIncludeResolver creates resolver of the directives enabled for the config.
+func (c *Config) newIncludeResolver(files map[string]string) *includeResolver {
+	keys := []string{includeKey}
+	if c.plainIncludes {
+		keys = []string{plainIncludeKey, includeKey}
+	}
+	return &includeResolver{keys: keys, files: files}
+}
+
+// resolveIncludes replaces include directives of the section with the contents
+// of the referenced files. Directive '$include' (or 'include' enabled with
+// WithPlainIncludes) holds file path or glob pattern or the list of them,
+// relative paths are resolved against the directory of the including file.
+// Included files are merged into the section in order they are listed, glob
+// matches in lexicographical order; properties of the section itself take
+// precedence over the included ones. Format of included file is detected by
+// its extension, included files may include other files.
+//
+// Chain holds the including files starting from the config file, it's used for
+// cycle detection and error messages.
+func (r *includeResolver) resolveIncludes(props map[string]interface{}, path, prefix string, chain []string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for _, key := range r.keys {
+		directive, ok := props[key]
+		if !ok {
+			continue
+		}
+		patterns, err := includePatterns(directive)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s of %s: %w", formatChain(chain), key, keyOrRoot(prefix), err)
+		}
+		for _, pattern := range patterns {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+			r.patterns = append(r.patterns, pattern)
+			matches, err := matchIncludes(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", formatChain(chain), err)
+			}
+			for _, match := range matches {
+				fragment, err := r.readInclude(match, prefix, chain)
+				if err != nil {
+					return nil, err
+				}
+				result = mergeProps(result, fragment)
+			}
+		}
+	}
+
+	root := len(chain) == 1
+	for key, val := range props {
+		if r.isDirective(key) {
+			continue
+		}
+		fullKey := joinKey(prefix, key)
+		if section, ok := val.(map[string]interface{}); ok {
+			resolved, err := r.resolveIncludes(section, path, fullKey, chain)
+			if err != nil {
+				return nil, err
+			}
+			val = resolved
+		} else if root {
+			// the value of config file overrides the included one
+			delete(r.files, fullKey)
+		} else {
+			r.files[fullKey] = path
+		}
+		result = mergeProps(result, map[string]interface{}{key: val})
+	}
+	return result, nil
+}
+
+func (r *includeResolver) isDirective(key string) bool {
+	for _, directiveKey := range r.keys {
+		if key == directiveKey {
+			return true
+		}
+	}
+	return false
+}
+
+// readInclude reads, parses and resolves includes of the included file.
+func (r *includeResolver) readInclude(path, prefix string, chain []string) (map[string]interface{}, error) {
+	chain = append(append([]string{}, chain...), path)
+	if isInChain(path, chain[:len(chain)-1]) {
+		return nil, fmt.Errorf("include cycle: %s", formatChain(chain))
+	}
+	format, ok := FormatByExtension(path)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown format of included file", formatChain(chain))
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", formatChain(chain), err)
+	}
+	fragment, err := parseWithFormat(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", formatChain(chain), err)
+	}
+	return r.resolveIncludes(fragment, path, prefix, chain)
+}
+
+func includePatterns(directive interface{}) ([]string, error) {
+	switch typed := directive.(type) {
+	case string:
+		return []string{typed}, nil
+	case []interface{}:
+		patterns := make([]string, 0, len(typed))
+		for _, elem := range typed {
+			pattern, ok := elem.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected string or list of strings, got %v", directive)
+			}
+			patterns = append(patterns, pattern)
+		}
+		return patterns, nil
+	default:
+		return nil, fmt.Errorf("expected string or list of strings, got %v", directive)
+	}
+}
+
+// matchIncludes returns files matching glob pattern sorted lexicographically.
+// Glob pattern may match no files, but plain path must point to existing file.
+func matchIncludes(pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("malformed include pattern %s: %w", pattern, err)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func isInChain(path string, chain []string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, including := range chain {
+		if includingAbs, err := filepath.Abs(including); err == nil && includingAbs == abs {
+			return true
+		}
+	}
+	return false
+}
+
+// formatChain formats the include chain, e.g. 'config.yaml -> teams/a.yaml'.
+func formatChain(chain []string) string {
+	return strings.Join(chain, " -> ")
+}
+
+func keyOrRoot(key string) string {
+	if key == "" {
+		return "root"
+	}
+	return key
+}
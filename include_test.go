@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	assertions "github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestConfig_Include(t *testing.T) {
+	assert := assertions.New(t)
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "$include: base.yaml\n" +
+			"app:\n  name: main\n" +
+			"teams:\n  $include: [teams/*.yaml, extra.json]\n  payments:\n    owner: override\n",
+		"base.yaml":           "app:\n  name: base\n  port: 8080\nlevel: info\n",
+		"teams/payments.yaml": "payments:\n  owner: alice\n  queue: pay\n",
+		"teams/search.yaml":   "search:\n  owner: bob\n  $include: ../common.yaml\n",
+		"common.yaml":         "timeout: 30\n",
+		"extra.json":          `{"search": {"owner": "carol"}}`,
+	})
+
+	config := NewConfig(filepath.Join(dir, "config.yaml"), Yaml)
+	assert.Equal("main", config.GetString("app.name"))
+	assert.Equal(8080, config.GetInt("app.port"))
+	assert.Equal("info", config.GetString("level"))
+	assert.Equal("override", config.GetString("teams.payments.owner"))
+	assert.Equal("pay", config.GetString("teams.payments.queue"))
+	assert.Equal("carol", config.GetString("teams.search.owner"))
+	assert.Equal(30, config.GetInt("teams.search.timeout"))
+	assert.False(config.IsSet("$include"))
+	assert.False(config.IsSet("teams.$include"))
+
+	assert.Equal(filepath.Join(dir, "base.yaml"), config.Explain("app.port").Source.Name)
+	assert.Equal(filepath.Join(dir, "config.yaml"), config.Explain("app.name").Source.Name)
+	assert.Equal(filepath.Join(dir, "common.yaml"), config.Explain("teams.search.timeout").Source.Name)
+}
+
+func TestConfig_IncludeErrors(t *testing.T) {
+	assert := assertions.New(t)
+	dir := writeFiles(t, map[string]string{
+		"cycle.yaml":   "$include: a.yaml\n",
+		"a.yaml":       "$include: b.yaml\n",
+		"b.yaml":       "$include: a.yaml\n",
+		"missing.yaml": "nested:\n  $include: none.yaml\n",
+		"broken.yaml":  "$include: [c.yaml]\n",
+		"c.yaml":       "key: [1\n",
+		"invalid.yaml": "$include: 42\n",
+	})
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	for name, expected := range map[string]string{
+		"cycle.yaml": fmt.Sprintf("include cycle: %s -> %s -> %s -> %s",
+			path("cycle.yaml"), path("a.yaml"), path("b.yaml"), path("a.yaml")),
+		"broken.yaml":  fmt.Sprintf("%s -> %s: yaml:", path("broken.yaml"), path("c.yaml")),
+		"invalid.yaml": fmt.Sprintf("%s: $include of root: expected string or list of strings, got 42", path("invalid.yaml")),
+	} {
+		func() {
+			defer func() {
+				r := recover()
+				assert.NotNil(r, name)
+				assert.Contains(fmt.Sprint(r), expected, name)
+			}()
+			_ = NewConfig(path(name), Yaml)
+		}()
+	}
+
+	defer func() {
+		r := recover()
+		assert.NotNil(r)
+		assert.Contains(fmt.Sprint(r), path("none.yaml"))
+	}()
+	_ = NewConfig(path("missing.yaml"), Yaml)
+}
+
+func TestConfig_IncludePlainKey(t *testing.T) {
+	assert := assertions.New(t)
+	dir := writeFiles(t, map[string]string{
+		"config.yaml": "features:\n  include: true\n  exclude: [beta]\n",
+	})
+
+	config := NewConfig(filepath.Join(dir, "config.yaml"), Yaml)
+	assert.True(config.GetBool("features.include"))
+	assert.Equal([]string{"beta"}, config.GetStringSlice("features.exclude"))
+}
+
+func TestConfig_PlainIncludes(t *testing.T) {
+	assert := assertions.New(t)
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":  "include: base.yaml\nteams:\n  include: [teams/*.yaml]\n  $include: extra.yaml\n",
+		"base.yaml":    "app:\n  name: base\n",
+		"teams/a.yaml": "a:\n  owner: alice\n",
+		"extra.yaml":   "a:\n  owner: carol\n",
+	})
+
+	config := NewConfig(filepath.Join(dir, "config.yaml"), Yaml, WithPlainIncludes())
+	assert.Equal("base", config.GetString("app.name"))
+	// '$include' is resolved after 'include'
+	assert.Equal("carol", config.GetString("teams.a.owner"))
+	assert.False(config.IsSet("include"))
+	assert.False(config.IsSet("teams.include"))
+
+	config = NewConfig(filepath.Join(dir, "config.yaml"), Yaml)
+	assert.Equal("base.yaml", config.GetString("include"))
+	assert.False(config.IsSet("app.name"))
+}
+
+func TestConfig_WatchReloadIncludes(t *testing.T) {
+	assert := assertions.New(t)
+	dir := writeFiles(t, map[string]string{
+		"config.yaml":  "$include: [base.yaml, teams/*.yaml]\n",
+		"base.yaml":    "app:\n  name: base\n",
+		"teams/a.yaml": "a:\n  owner: alice\n",
+	})
+	config := NewConfig(filepath.Join(dir, "config.yaml"), Yaml)
+	stop := config.WatchReload(5 * time.Millisecond)
+	defer stop()
+
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(ioutil.WriteFile(path, []byte(content), 0644))
+		// modification time may be unchanged on coarse-grained file systems
+		future := time.Now().Add(time.Hour)
+		assert.NoError(os.Chtimes(path, future, future))
+	}
+	writeFile("base.yaml", "app:\n  name: updated\n")
+	assert.Eventually(func() bool {
+		return config.GetString("app.name") == "updated"
+	}, time.Second, 5*time.Millisecond)
+
+	writeFile("teams/b.yaml", "b:\n  owner: bob\n")
+	assert.Eventually(func() bool {
+		return config.GetString("b.owner") == "bob"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestConfig_FileSourceLongestPrefix(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := &Config{filePath: "config.yaml", keyFiles: map[string]string{
+		"db":           "db.yaml",
+		"db.replica":   "replica.yaml",
+		"db.replica.x": "x.yaml",
+		"dbx":          "dbx.yaml",
+	}}
+	for i := 0; i < 10; i++ {
+		assert.Equal("replica.yaml", config.fileSource("db.replica.host").Name)
+		assert.Equal("db.yaml", config.fileSource("db.host").Name)
+		assert.Equal("config.yaml", config.fileSource("cache").Name)
+	}
+}
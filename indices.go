@@ -0,0 +1,78 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// wildcardIndex selects every element of the list, e.g. 'servers[*].host'.
+const wildcardIndex = "*"
+
+// parseIndexedSegment splits key segment like 'servers[0][1]' into the property
+// name 'servers' and the list of indices '0', '1'. Segment without brackets is
+// returned as is. The last returned value is false if brackets are malformed.
+func parseIndexedSegment(segment string) (string, []string, bool) {
+	openIdx := strings.Index(segment, "[")
+	if openIdx == -1 {
+		return segment, nil, true
+	}
+	name := segment[:openIdx]
+	var indices []string
+	rest := segment[openIdx:]
+	for rest != "" {
+		closeIdx := strings.Index(rest, "]")
+		if rest[0] != '[' || closeIdx == -1 {
+			return "", nil, false
+		}
+		index := rest[1:closeIdx]
+		if index != wildcardIndex {
+			if _, err := strconv.Atoi(index); err != nil {
+				return "", nil, false
+			}
+		}
+		indices = append(indices, index)
+		rest = rest[closeIdx+1:]
+	}
+	return name, indices, true
+}
+
+// resolveIndexed applies list indices to the value and then looks up the rest
+// of the key in the resulting section. Negative index counts from the end of
+// the list, e.g. 'servers[-1]' is the last server. Wildcard index collects
+// the values found in every element of the list into a new list, the key is
+// not found if none of the elements has the value.
+func resolveIndexed(val interface{}, indices []string, rest string) (interface{}, bool) {
+	if len(indices) == 0 {
+		if rest == "" {
+			return val, true
+		}
+		if submap, ok := val.(map[string]interface{}); ok {
+			return lookupProp(rest, submap)
+		}
+		return nil, false
+	}
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	if indices[0] == wildcardIndex {
+		results := make([]interface{}, 0, len(list))
+		for _, elem := range list {
+			if found, isFound := resolveIndexed(elem, indices[1:], rest); isFound {
+				results = append(results, found)
+			}
+		}
+		if len(results) == 0 {
+			return nil, false
+		}
+		return results, true
+	}
+	idx, _ := strconv.Atoi(indices[0])
+	if idx < 0 {
+		idx += len(list)
+	}
+	if idx < 0 || idx >= len(list) {
+		return nil, false
+	}
+	return resolveIndexed(list[idx], indices[1:], rest)
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	assertions "github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestConfig_ListIndices(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_lists.yaml", Yaml)
+
+	assert.Equal("alpha.example.com", config.GetString("servers[0].host"))
+	assert.Equal("beta.example.com", config.RequireString("servers[1].host"))
+	assert.Equal(8082, config.GetInt("servers[2].port"))
+	assert.Equal(8082, config.RequireInt("servers[-1].port"))
+	assert.Equal(float64(8081), config.GetFloat64("servers[-2].port"))
+	assert.Equal(float32(8080), config.GetFloat32("servers[-3].port"))
+	assert.True(config.GetBool("servers[0].tls"))
+	assert.Equal("g", config.GetString("servers[2].aliases[1]"))
+	assert.Equal("g", config.GetString("servers[-1].aliases[-1]"))
+	assert.Equal("broker2", config.GetString("kafka.brokers[1]"))
+	assert.Equal(21, config.GetInt("kafka.matrix[1][0]"))
+	assert.Equal([]interface{}{"broker1", "broker2"}, config.GetProp("kafka.brokers"))
+
+	assert.Nil(config.GetProp("servers[3].host"))
+	assert.Nil(config.GetProp("servers[-4].host"))
+	assert.Nil(config.GetProp("servers[0].missing"))
+	assert.Nil(config.GetProp("servers[x].host"))
+	assert.Nil(config.GetProp("servers[0.host"))
+	assert.Nil(config.GetProp("kafka[0]"))
+	assert.Equal("default", config.GetString("servers[5].host", "default"))
+	assert.True(config.IsSet("servers[1].port"))
+	assert.False(config.IsSet("servers[1].tls"))
+}
+
+func TestConfig_ListWildcard(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_lists.yaml", Yaml)
+
+	assert.Equal([]interface{}{"alpha.example.com", "beta.example.com", "gamma.example.com"},
+		config.GetProp("servers[*].host"))
+	assert.Equal([]interface{}{true}, config.GetProp("servers[*].tls"))
+	assert.Equal([]interface{}{[]interface{}{"gamma", "g"}}, config.GetProp("servers[*].aliases"))
+	assert.Equal([]interface{}{float64(12), float64(22)}, config.GetProp("kafka.matrix[*][1]"))
+	assert.Nil(config.GetProp("servers[*].missing"))
+	assert.Equal("default", config.GetString("servers[*].missing", "default"))
+	assert.Equal("[broker1 broker2]", config.GetString("kafka.brokers[*]"))
+}
+
+func TestConfig_ListWildcardTypedGetters(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_lists.yaml", Yaml)
+
+	assert.Equal([]string{"alpha.example.com", "beta.example.com", "gamma.example.com"},
+		config.GetStringSlice("servers[*].host"))
+	assert.Equal([]int{8080, 8081, 8082}, config.GetIntSlice("servers[*].port"))
+	assert.Equal([]float64{8080, 8081, 8082}, config.GetFloat64Slice("servers[*].port"))
+	assert.Equal([]bool{true}, config.GetBoolSlice("servers[*].tls"))
+	assert.Equal([]string{"broker2"}, config.GetStringSlice("kafka.brokers[1]"))
+	assert.Equal([]int{1, 2}, config.GetIntSlice("servers[*].missing", []int{1, 2}))
+	assert.Nil(config.GetStringSlice("servers[*].missing"))
+
+	assert.NoError(os.Setenv("SLICE_PORTS", "1, 2,3"))
+	defer os.Unsetenv("SLICE_PORTS")
+	assert.Equal([]int{1, 2, 3}, config.GetIntSlice("slice.ports"))
+
+	defer func() {
+		r := recover()
+		assert.NotNil(r)
+		assert.Contains(fmt.Sprint(r), "Property servers[*].port holds a list, use slice getters")
+	}()
+	_ = config.GetInt("servers[*].port")
+}
+
+func TestConfig_ListIndicesEnvs(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_lists.yaml", Yaml)
+
+	err := os.Setenv("SERVERS_3_HOST", "delta.example.com")
+	assert.Nil(err)
+	err = os.Setenv("SERVERS_1_TLS", "true")
+	assert.Nil(err)
+	err = os.Setenv("SERVERS_0_HOST", "env.example.com")
+	assert.Nil(err)
+
+	assert.Equal("delta.example.com", config.GetString("servers[3].host"))
+	assert.True(config.GetBool("servers[1].tls"))
+	assert.Equal("alpha.example.com", config.GetString("servers[0].host"))
+
+	err = os.Unsetenv("SERVERS_3_HOST")
+	assert.Nil(err)
+	err = os.Unsetenv("SERVERS_1_TLS")
+	assert.Nil(err)
+	err = os.Unsetenv("SERVERS_0_HOST")
+	assert.Nil(err)
+}
+
+func TestEnvVarName(t *testing.T) {
+	assert := assertions.New(t)
+
+	assert.Equal("MY_TEST_PROPERTY1", envVarName("my.test.property1"))
+	assert.Equal("SERVERS_0_HOST", envVarName("servers[0].host"))
+	assert.Equal("KAFKA_MATRIX_1_0", envVarName("kafka.matrix[1][0]"))
+	assert.Equal("", envVarName("servers[-1].host"))
+	assert.Equal("", envVarName("servers[*].host"))
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// iniToJSON converts INI document to JSON. Entries 'key=value' (or 'key: value')
+// of '[section]' are stored under the section key, sections and keys containing
+// dots are expanded into nested sections, e.g. 'key=value' of '[a.b]' is
+// resolved by the key 'a.b.key'. Entries before the first section are top-level.
+//
+// Lines starting with ';' or '#' are comments, lines ending with backslash are
+// continued on the next line. Inline comments start with ';' or '#' preceded by
+// whitespace. Values may be enclosed in double or single quotes to keep leading
+// and trailing whitespace or comment characters. Backslashes are kept as is,
+// e.g. 'C:\temp\new'. All values are strings.
+func iniToJSON(data []byte) ([]byte, error) {
+	props := make(map[string]interface{})
+	lines, err := logicalLines(data, func(trimmed string) bool {
+		return strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#")
+	})
+	if err != nil {
+		return nil, err
+	}
+	section := ""
+	for _, line := range lines {
+		text := strings.TrimSpace(line.text)
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("line %d: malformed section header %q", line.number, text)
+			}
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			continue
+		}
+		sepIdx := strings.IndexAny(text, "=:")
+		if sepIdx == -1 {
+			return nil, fmt.Errorf("line %d: missing '=' in %q", line.number, text)
+		}
+		key := strings.TrimSpace(text[:sepIdx])
+		setNestedProp(props, joinKey(section, key), parseIniValue(text[sepIdx+1:]))
+	}
+	return json.Marshal(props)
+}
+
+// parseIniValue strips inline comment and unquotes the value. Quoted value
+// is taken as is if only whitespace or comment follows the closing quote.
+func parseIniValue(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	if len(trimmed) >= 2 && (trimmed[0] == '"' || trimmed[0] == '\'') {
+		if end := strings.IndexByte(trimmed[1:], trimmed[0]) + 1; end > 0 {
+			rest := strings.TrimSpace(trimmed[end+1:])
+			if rest == "" || rest[0] == ';' || rest[0] == '#' {
+				return trimmed[1:end]
+			}
+		}
+	}
+	for i := 1; i < len(raw); i++ {
+		if (raw[i] == ';' || raw[i] == '#') && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			return strings.TrimSpace(raw[:i])
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfig_Ini(t *testing.T) {
+	config := NewConfig("./test_config.ini", Ini)
+	testConfigPositiveCases(t, config)
+}
+
+func TestConfig_IniValues(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.ini", Ini)
+
+	assert.Equal("  padded  ", config.GetString("escapes.quoted"))
+	assert.Equal("first, second", config.GetString("escapes.multiline"))
+	assert.Equal(`C:\temp\new`, config.GetString("escapes.path"))
+	assert.Equal("value", config.GetString("escapes.inline"))
+	assert.Equal("value", config.GetString("escapes.hash"))
+	assert.Equal("#fff", config.GetString("escapes.color"))
+	assert.Equal("http://example.com/#anchor;x", config.GetString("escapes.url"))
+	assert.Equal("a ; b", config.GetString("escapes.quoted.comment"))
+	assert.Equal("", config.GetString("escapes.empty", "default"))
+	assert.Equal([]string{"family1", "family2", "family3"}, config.Keys("root"))
+}
+
+func TestConfig_IniParsingErr(t *testing.T) {
+	assert := assertions.New(t)
+
+	_, err := iniToJSON([]byte("[section\nkey=value"))
+	assert.EqualError(err, `line 1: malformed section header "[section"`)
+	_, err = iniToJSON([]byte("[section]\nkey value"))
+	assert.EqualError(err, `line 2: missing '=' in "key value"`)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on ini parsing error")
+		}
+	}()
+	_ = NewConfig("./test_config.yaml", Ini)
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	referenceStart   = "${"
+	escapedReference = "$${"
+	defaultSeparator = ":-"
+)
+
+// WithoutExpansion disables expansion of references in values of the specified
+// properties, e.g. for secrets that may contain '${' sequence. If no keys are
+// specified, expansion is disabled for all properties.
+func WithoutExpansion(keys ...string) Option {
+	return func(c *Config) {
+		if len(keys) == 0 {
+			c.expandNone = true
+		}
+		c.noExpansion = append(c.noExpansion, keys...)
+	}
+}
+
+// expandProp expands references in the string value of the property.
+// Argument stack holds keys being expanded and is used to detect cycles,
+// referenced keys are recorded as consumed if track is true.
+func (c *Config) expandProp(key string, prop interface{}, stack []string, track bool) interface{} {
+	strProp, ok := prop.(string)
+	if !ok || !strings.Contains(strProp, referenceStart) || !c.isExpansionEnabled(key) {
+		return prop
+	}
+	return c.expandString(strProp, append(stack, c.normalizeKey(key)), track)
+}
+
+func (c *Config) isExpansionEnabled(key string) bool {
+	if c.expandNone {
+		return false
+	}
+	key = c.normalizeKey(key)
+	for _, disabled := range c.noExpansion {
+		if c.normalizeKey(disabled) == key {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) expandString(str string, stack []string, track bool) string {
+	var builder strings.Builder
+	for i := 0; i < len(str); {
+		if strings.HasPrefix(str[i:], escapedReference) {
+			builder.WriteString(referenceStart)
+			i += len(escapedReference)
+			continue
+		}
+		if strings.HasPrefix(str[i:], referenceStart) {
+			end := findClosingBrace(str, i+len(referenceStart))
+			if end == -1 {
+				builder.WriteString(str[i:])
+				break
+			}
+			builder.WriteString(c.resolveReference(str[i+len(referenceStart):end], stack, track))
+			i = end + 1
+			continue
+		}
+		builder.WriteByte(str[i])
+		i++
+	}
+	return builder.String()
+}
+
+// resolveReference returns value for the reference like 'key.path' or 'ENV_VAR:-default'.
+// Property with the referenced key is looked up first, then the environment variable.
+func (c *Config) resolveReference(reference string, stack []string, track bool) string {
+	name := reference
+	defaultVal, hasDefault := "", false
+	if sepIdx := strings.Index(reference, defaultSeparator); sepIdx != -1 {
+		name = reference[:sepIdx]
+		defaultVal, hasDefault = reference[sepIdx+len(defaultSeparator):], true
+	}
+	for _, key := range stack {
+		if key == c.normalizeKey(name) {
+			log.Panicf("Cyclic reference in property %s: %s", stack[0],
+				strings.Join(append(stack, name), " -> "))
+		}
+	}
+
+	var val string
+	if prop := c.findRawProp(name, track); prop != nil {
+		val = fmt.Sprintf("%v", c.expandProp(name, prop, stack, track))
+	} else {
+		val = c.readStringFromEnv(name)
+	}
+	if val == "" && hasDefault {
+		return c.expandString(defaultVal, stack, track)
+	}
+	return val
+}
+
+// findClosingBrace returns index of the brace closing the reference started
+// before the index from, taking nested references into account.
+func findClosingBrace(str string, from int) int {
+	depth := 1
+	for i := from; i < len(str); i++ {
+		switch str[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestConfig_Interpolation(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_interpolation.yaml", Yaml)
+
+	assert.Equal("https://localhost:8443", config.GetString("base.url"))
+	assert.Equal("https://localhost:8443/users", config.GetString("services.users"))
+	assert.Equal("https://localhost:8443/orders", config.RequireString("services.orders"))
+	assert.Equal(30, config.GetInt("services.timeout"))
+	assert.Equal(30, config.RequireInt("services.timeout"))
+	assert.Equal(0.5, config.GetFloat64("services.ratio"))
+	assert.Equal(float32(0.5), config.RequireFloat32("services.ratio"))
+	assert.True(config.GetBool("services.enabled"))
+	assert.True(config.RequireBool("services.enabled"))
+	assert.Equal("prefix--suffix", config.GetString("services.missing"))
+	assert.Equal("8443", config.GetString("services.nested_default"))
+	assert.Equal("${base.port} is 8443", config.GetString("services.escaped"))
+	assert.Equal("${base.port", config.GetString("services.unclosed"))
+	assert.Equal("pa${word8443", config.GetString("secret.password"))
+}
+
+func TestConfig_InterpolationEnvs(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_interpolation.yaml", Yaml)
+
+	err := os.Setenv("HOST_NAME", "example.com")
+	assert.Nil(err)
+	err = os.Setenv("TEST_TIMEOUT", "45")
+	assert.Nil(err)
+
+	assert.Equal("https://example.com:8443/users", config.GetString("services.users"))
+	assert.Equal(45, config.GetInt("services.timeout"))
+
+	err = os.Unsetenv("HOST_NAME")
+	assert.Nil(err)
+	err = os.Unsetenv("TEST_TIMEOUT")
+	assert.Nil(err)
+}
+
+func TestConfig_WithoutExpansion(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_interpolation.yaml", Yaml, WithoutExpansion("secret.password"))
+	assert.Equal("pa$${word${base.port}", config.GetString("secret.password"))
+	assert.Equal("https://localhost:8443", config.GetString("base.url"))
+
+	config = NewConfig("./test_config_interpolation.yaml", Yaml, WithoutExpansion())
+	assert.Equal("https://${HOST_NAME:-localhost}:${base.port}", config.GetString("base.url"))
+}
+
+func TestConfig_InterpolationCycle(t *testing.T) {
+	config := NewConfig("./test_config_interpolation.yaml", Yaml)
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Errorf("Expected panic on cyclic reference")
+		}
+		assertions.New(t).Contains(r, "cycle.a -> cycle.b -> cycle.c -> cycle.a")
+	}()
+	config.GetString("cycle.a")
+}
+
+func TestConfig_InterpolationSelfReference(t *testing.T) {
+	config := NewConfig("./test_config_interpolation.yaml", Yaml)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on cyclic reference")
+		}
+	}()
+	config.GetString("cycle.self")
+}
+
+func TestConfig_GetInt_PropertyParsingErr(t *testing.T) {
+	config := NewConfig("./test_config.yaml", Yaml)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic in parsing property value")
+		}
+	}()
+	config.GetInt("root.family1.key1")
+}
@@ -0,0 +1,271 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// json5ToJSON converts JSON with comments or JSON5 document to standard JSON.
+// Supported extensions: line and block comments, trailing commas in objects and
+// arrays, unquoted identifier keys, single-quoted strings, strings continued on
+// the next line with backslash, hexadecimal numbers, numbers with leading plus
+// sign or leading or trailing decimal point. Infinity and NaN are not supported
+// since they can't be represented in JSON.
+func json5ToJSON(data []byte) ([]byte, error) {
+	conv := json5Converter{src: string(data)}
+	if err := conv.convert(); err != nil {
+		return nil, err
+	}
+	return []byte(conv.out.String()), nil
+}
+
+type json5Converter struct {
+	src string
+	pos int
+	out strings.Builder
+	// pendingComma is set when comma was read but not written yet,
+	// it's dropped if followed by closing bracket.
+	pendingComma bool
+}
+
+func (c *json5Converter) convert() error {
+	for {
+		if err := c.skipWhitespaceAndComments(); err != nil {
+			return err
+		}
+		if c.pos >= len(c.src) {
+			if c.pendingComma {
+				c.out.WriteByte(',')
+			}
+			return nil
+		}
+		ch := c.src[c.pos]
+		switch {
+		case ch == ',':
+			if c.pendingComma {
+				return c.errorf("unexpected ','")
+			}
+			c.pendingComma = true
+			c.pos++
+			continue
+		case ch == '}' || ch == ']':
+			c.pendingComma = false
+			c.out.WriteByte(ch)
+			c.pos++
+			continue
+		}
+		c.flushComma()
+		switch {
+		case ch == '{' || ch == '[' || ch == ':':
+			c.out.WriteByte(ch)
+			c.pos++
+		case ch == '"' || ch == '\'':
+			if err := c.convertString(ch); err != nil {
+				return err
+			}
+		case ch == '+' || ch == '-' || ch == '.' || (ch >= '0' && ch <= '9'):
+			if err := c.convertNumber(); err != nil {
+				return err
+			}
+		case isIdentifierStart(ch):
+			if err := c.convertIdentifier(); err != nil {
+				return err
+			}
+		default:
+			return c.errorf("unexpected character %q", ch)
+		}
+	}
+}
+
+func (c *json5Converter) flushComma() {
+	if c.pendingComma {
+		c.out.WriteByte(',')
+		c.pendingComma = false
+	}
+}
+
+func (c *json5Converter) skipWhitespaceAndComments() error {
+	for c.pos < len(c.src) {
+		switch {
+		case strings.ContainsRune(" \t\r\n\f\v", rune(c.src[c.pos])):
+			c.pos++
+		case strings.HasPrefix(c.src[c.pos:], "//"):
+			if end := strings.IndexByte(c.src[c.pos:], '\n'); end != -1 {
+				c.pos += end + 1
+			} else {
+				c.pos = len(c.src)
+			}
+		case strings.HasPrefix(c.src[c.pos:], "/*"):
+			end := strings.Index(c.src[c.pos+2:], "*/")
+			if end == -1 {
+				return c.errorf("unterminated block comment")
+			}
+			c.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (c *json5Converter) convertString(quote byte) error {
+	start := c.pos
+	c.pos++
+	var builder strings.Builder
+	for c.pos < len(c.src) {
+		ch := c.src[c.pos]
+		switch {
+		case ch == quote:
+			c.pos++
+			encoded, _ := json.Marshal(builder.String())
+			c.out.Write(encoded)
+			return nil
+		case ch == '\n':
+			return c.errorf("unterminated string")
+		case ch == '\\':
+			if c.pos+1 >= len(c.src) {
+				return c.errorf("unterminated string")
+			}
+			c.pos++
+			if err := c.unescape(&builder); err != nil {
+				return err
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(c.src[c.pos:])
+			builder.WriteRune(r)
+			c.pos += size
+		}
+	}
+	c.pos = start
+	return c.errorf("unterminated string")
+}
+
+// unescape writes the character of escape sequence starting at current position
+// (after the backslash) to the builder.
+func (c *json5Converter) unescape(builder *strings.Builder) error {
+	ch := c.src[c.pos]
+	c.pos++
+	switch ch {
+	case 'b':
+		builder.WriteByte('\b')
+	case 'f':
+		builder.WriteByte('\f')
+	case 'n':
+		builder.WriteByte('\n')
+	case 'r':
+		builder.WriteByte('\r')
+	case 't':
+		builder.WriteByte('\t')
+	case 'v':
+		builder.WriteByte('\v')
+	case '0':
+		builder.WriteByte(0)
+	case '\n':
+		// line continuation
+	case '\r':
+		if c.pos < len(c.src) && c.src[c.pos] == '\n' {
+			c.pos++
+		}
+	case 'x', 'u':
+		size := 2
+		if ch == 'u' {
+			size = 4
+		}
+		if c.pos+size > len(c.src) {
+			return c.errorf("malformed \\%c escape", ch)
+		}
+		code, err := strconv.ParseUint(c.src[c.pos:c.pos+size], 16, 32)
+		if err != nil {
+			return c.errorf("malformed \\%c escape", ch)
+		}
+		c.pos += size
+		r := rune(code)
+		// high surrogate is joined with the low surrogate escape following it
+		if ch == 'u' && utf16.IsSurrogate(r) && strings.HasPrefix(c.src[c.pos:], `\u`) && c.pos+6 <= len(c.src) {
+			if low, err := strconv.ParseUint(c.src[c.pos+2:c.pos+6], 16, 32); err == nil {
+				if pair := utf16.DecodeRune(r, rune(low)); pair != utf8.RuneError {
+					r = pair
+					c.pos += 6
+				}
+			}
+		}
+		builder.WriteRune(r)
+	default:
+		builder.WriteByte(ch)
+	}
+	return nil
+}
+
+func (c *json5Converter) convertNumber() error {
+	start := c.pos
+	for c.pos < len(c.src) && strings.ContainsRune("+-.0123456789abcdefABCDEFxX", rune(c.src[c.pos])) {
+		c.pos++
+	}
+	literal := strings.TrimPrefix(c.src[start:c.pos], "+")
+	sign := ""
+	if strings.HasPrefix(literal, "-") {
+		sign, literal = "-", literal[1:]
+	}
+	if strings.HasPrefix(literal, "0x") || strings.HasPrefix(literal, "0X") {
+		val, err := strconv.ParseUint(literal[2:], 16, 64)
+		if err != nil {
+			return c.errorf("malformed number %q", c.src[start:c.pos])
+		}
+		c.out.WriteString(sign + strconv.FormatUint(val, 10))
+		return nil
+	}
+	if _, err := strconv.ParseFloat(literal, 64); err != nil || strings.ContainsAny(literal, "xX") {
+		return c.errorf("malformed number %q", c.src[start:c.pos])
+	}
+	if strings.HasPrefix(literal, ".") {
+		literal = "0" + literal
+	}
+	literal = strings.Replace(literal, ".e", ".0e", 1)
+	literal = strings.Replace(literal, ".E", ".0E", 1)
+	if strings.HasSuffix(literal, ".") {
+		literal += "0"
+	}
+	c.out.WriteString(sign + literal)
+	return nil
+}
+
+// convertIdentifier writes literals true, false and null as is and quotes other
+// identifiers, which are only valid as object keys.
+func (c *json5Converter) convertIdentifier() error {
+	start := c.pos
+	for c.pos < len(c.src) && (isIdentifierStart(c.src[c.pos]) || (c.src[c.pos] >= '0' && c.src[c.pos] <= '9')) {
+		c.pos++
+	}
+	identifier := c.src[start:c.pos]
+	switch identifier {
+	case "true", "false", "null":
+		c.out.WriteString(identifier)
+		return nil
+	}
+	end := c.pos
+	if err := c.skipWhitespaceAndComments(); err != nil {
+		return err
+	}
+	isKey := c.pos < len(c.src) && c.src[c.pos] == ':'
+	c.pos = end
+	if !isKey {
+		c.pos = start
+		return c.errorf("unexpected identifier %q", identifier)
+	}
+	encoded, _ := json.Marshal(identifier)
+	c.out.Write(encoded)
+	return nil
+}
+
+func (c *json5Converter) errorf(format string, args ...interface{}) error {
+	line := strings.Count(c.src[:c.pos], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func isIdentifierStart(ch byte) bool {
+	return ch == '_' || ch == '$' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
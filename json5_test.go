@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/json"
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfig_Jsonc(t *testing.T) {
+	config := NewConfig("./test_config.jsonc", Jsonc)
+	testConfigPositiveCases(t, config)
+}
+
+func TestConfig_Json5(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.json5", Json5)
+	testConfigPositiveCases(t, config)
+
+	assert.Equal(`it's "quoted"`, config.GetString("extras.$id"))
+	assert.Equal(0.5, config.GetFloat64("extras.ratio"))
+	assert.Equal(-16, config.GetInt("extras.negative"))
+	assert.Equal(100, config.GetInt("extras.exponent"))
+	assert.Equal("éA", config.GetString("extras.unicode"))
+	assert.Equal([]interface{}{float64(1), float64(2), float64(3)}, config.GetProp("extras.list"))
+}
+
+func TestJson5ToJSON_SurrogatePairs(t *testing.T) {
+	assert := assertions.New(t)
+
+	plane, err := json5ToJSON([]byte(`{"smile": "\ud83d\ude00", "lone": '\ud83d!', "a": "\u00e9"}`))
+	assert.NoError(err)
+	var props map[string]interface{}
+	assert.NoError(json.Unmarshal(plane, &props))
+	assert.Equal("\U0001F600", props["smile"])
+	assert.Equal("\uFFFD!", props["lone"])
+	assert.Equal("\u00e9", props["a"])
+}
+
+func TestJson5ToJSON_Errors(t *testing.T) {
+	assert := assertions.New(t)
+
+	for src, expected := range map[string]string{
+		`{"a": 1,, "b": 2}`: "line 1: unexpected ','",
+		"{\n/* open":        "line 2: unterminated block comment",
+		`{"a": 'open}`:      "line 1: unterminated string",
+		"{\"a\": \"x\ny\"}": "line 1: unterminated string",
+		`{"a": Infinity}`:   `line 1: unexpected identifier "Infinity"`,
+		`{"a": 0xZZ}`:       `line 1: malformed number "0x"`,
+		`{"a": 1.2.3}`:      `line 1: malformed number "1.2.3"`,
+		`{"a": '\u12'}`:     `line 1: malformed \u escape`,
+		`{"a": #}`:          `line 1: unexpected character '#'`,
+	} {
+		_, err := json5ToJSON([]byte(src))
+		assert.EqualError(err, expected, src)
+	}
+}
+
+func TestConfig_Json5ParsingErr(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on json5 parsing error")
+		}
+	}()
+	_ = NewConfig("./test_config.yaml", Json5)
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// AllKeys returns fully qualified dotted keys of all properties read from file.
+// Both nested sections and literal dotted keys are flattened, e.g. property
+// 'family3.key1' declared inside section 'root' is returned as 'root.family3.key1'.
+// Keys are sorted in lexicographical order.
+func (c *Config) AllKeys() []string {
+	keys := make([]string, 0)
+	walkProps("", c.props(), func(key string, _ interface{}) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// IsSet reports whether the property for the specified key is explicitly set
+// either in config file or in the environment variable. Unlike GetProp it
+// returns true for properties holding zero values (empty string, 0, false or null)
+// and for sections containing nested properties. Environment variables set to
+// empty string are treated as unset, the same way getters fall back to defaults.
+func (c *Config) IsSet(key string) bool {
+	c.markConsumed(c.normalizeKey(key))
+	for _, candidate := range c.keyCandidates(key) {
+		if c.isSetExactly(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSetExactly reports whether the key is set in flags, file or env ignoring aliases.
+// The key is expected to be normalized already.
+func (c *Config) isSetExactly(key string) bool {
+	if _, found := c.lookupFlag(key); found {
+		return true
+	}
+	if _, found := lookupProp(key, c.props()); found {
+		return true
+	}
+	_, found := c.lookupEnv(envVarName(key))
+	return found
+}
+
+// Keys returns names of the direct children of the section specified by prefix.
+// Returned names are relative to the prefix, e.g. for the prefix 'root' it returns
+// 'family1', 'family2' and 'family3'. Empty prefix lists top-level properties.
+// Names are sorted in lexicographical order; nil is returned if the section is missing.
+// With case-insensitive keys the prefix is matched regardless of case.
+func (c *Config) Keys(prefix string) []string {
+	if prefix != "" {
+		prefix = strings.TrimSuffix(c.normalizeKey(prefix), ".") + "."
+	}
+	unique := make(map[string]bool)
+	for _, key := range c.AllKeys() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		child := key[len(prefix):]
+		if dotIdx := strings.Index(child, "."); dotIdx != -1 {
+			child = child[:dotIdx]
+		}
+		unique[child] = true
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+	children := make([]string, 0, len(unique))
+	for child := range unique {
+		children = append(children, child)
+	}
+	sort.Strings(children)
+	return children
+}
+
+// walkProps calls fn for each leaf property of the map with its fully qualified key.
+func walkProps(prefix string, props map[string]interface{}, fn func(key string, val interface{})) {
+	for key, val := range props {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+		if submap, ok := val.(map[string]interface{}); ok && len(submap) > 0 {
+			walkProps(fullKey, submap, fn)
+			continue
+		}
+		fn(fullKey, val)
+	}
+}
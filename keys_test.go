@@ -0,0 +1,73 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestConfig_AllKeys(t *testing.T) {
+	assert := assertions.New(t)
+
+	expected := []string{
+		"another.simple.prop",
+		"root.family1.key1",
+		"root.family1.key2.subkey1",
+		"root.family1.key2.subkey2",
+		"root.family2",
+		"root.family3.key1",
+		"root.family3.key2",
+		"simpleprop",
+		"subroot.family1.key1",
+		"subroot.family1.key2",
+		"subroot.family1.key2.subkey1",
+		"subroot.family1.key3.secret",
+	}
+	assert.Equal(expected, NewConfig("./test_config.yaml", Yaml).AllKeys())
+	assert.Equal(expected, NewConfig("./test_config.json", Json).AllKeys())
+}
+
+func TestConfig_AllKeysResolvable(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.yaml", Yaml)
+	for _, key := range config.AllKeys() {
+		assert.NotNil(config.GetProp(key), key)
+	}
+}
+
+func TestConfig_IsSet(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.yaml", Yaml)
+
+	assert.True(config.IsSet("root.family1.key1"))
+	assert.True(config.IsSet("root.family3.key2"))
+	assert.True(config.IsSet("root.family1.key2.subkey1"))
+	assert.True(config.IsSet("root.family1"))
+	assert.False(config.IsSet("root.family1.key19"))
+	assert.False(config.IsSet("missing_property"))
+
+	err := os.Setenv("ROOT_FAMILY1_KEY19", "")
+	assert.Nil(err)
+	assert.False(config.IsSet("root.family1.key19"))
+	assert.Nil(config.Explain("root.family1.key19").Source)
+	err = os.Setenv("ROOT_FAMILY1_KEY19", "value")
+	assert.Nil(err)
+	assert.True(config.IsSet("root.family1.key19"))
+	err = os.Unsetenv("ROOT_FAMILY1_KEY19")
+	assert.Nil(err)
+}
+
+func TestConfig_Keys(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.yaml", Yaml)
+
+	assert.Equal([]string{"another", "root", "simpleprop", "subroot"}, config.Keys(""))
+	assert.Equal([]string{"family1", "family2", "family3"}, config.Keys("root"))
+	assert.Equal([]string{"key1", "key2"}, config.Keys("root.family3"))
+	assert.Equal([]string{"subkey1", "subkey2"}, config.Keys("root.family1.key2."))
+	assert.Nil(config.Keys("root.family2"))
+	assert.Nil(config.Keys("missing"))
+}
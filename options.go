@@ -0,0 +1,43 @@
+package config
+
+import "log"
+
+// Logger is used by Config to report warnings, e.g. about ambiguous or deprecated keys.
+// Standard *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Option customizes Config on construction. Options are passed to NewConfig
+// as optional arguments and applied before the config file is read.
+type Option func(*Config)
+
+const (
+	// AmbiguityWarn makes NewConfig log every ambiguous key. This is the default mode.
+	AmbiguityWarn = iota
+	// AmbiguityError makes NewConfig panic if config file contains ambiguous keys.
+	AmbiguityError
+	// AmbiguityIgnore disables the check for ambiguous keys.
+	AmbiguityIgnore
+)
+
+// WithAmbiguityCheck sets the mode of the load-time check for keys reachable
+// in more than one way, e.g. 'key2.subkey1: x' and 'key2: {subkey1: y}'.
+// Argument mode is one of the constants: config.AmbiguityWarn,
+// config.AmbiguityError or config.AmbiguityIgnore.
+func WithAmbiguityCheck(mode int) Option {
+	return func(c *Config) {
+		c.ambiguityCheck = mode
+	}
+}
+
+// WithLogger sets the logger for warnings. By default the standard logger of
+// log package is used, nil logger keeps the default.
+func WithLogger(logger Logger) Option {
+	return func(c *Config) {
+		if logger == nil {
+			logger = log.Default()
+		}
+		c.logger = logger
+	}
+}
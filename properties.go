@@ -0,0 +1,197 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// propertiesToJSON converts Java .properties document to JSON. Flat keys like
+// 'a.b.c=value' are expanded into nested sections, see setNestedProp.
+// Comments, escapes and line continuations are handled according to the
+// java.util.Properties format. All values are strings.
+func propertiesToJSON(data []byte) ([]byte, error) {
+	props := make(map[string]interface{})
+	lines, err := logicalLines(data, func(trimmed string) bool {
+		return strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!")
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		key, val := splitPropertiesLine(line.text)
+		key, err = unescapeProperties(key)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		if val, err = unescapeProperties(val); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		setNestedProp(props, key, val)
+	}
+	return json.Marshal(props)
+}
+
+type logicalLine struct {
+	text   string
+	number int
+}
+
+// logicalLines joins natural lines ending with unescaped backslash with the
+// following line, stripping leading whitespace of the continuation. Blank lines
+// and lines recognized by isComment are skipped.
+func logicalLines(data []byte, isComment func(trimmed string) bool) ([]logicalLine, error) {
+	var lines []logicalLine
+	var current strings.Builder
+	continuation := false
+	start := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; scanner.Scan(); number++ {
+		natural := strings.TrimLeft(scanner.Text(), " \t\f")
+		if !continuation {
+			if natural == "" || isComment(natural) {
+				continue
+			}
+			start = number
+		}
+		if trailingBackslashes(natural)%2 == 1 {
+			current.WriteString(natural[:len(natural)-1])
+			continuation = true
+			continue
+		}
+		current.WriteString(natural)
+		lines = append(lines, logicalLine{text: current.String(), number: start})
+		current.Reset()
+		continuation = false
+	}
+	if continuation {
+		lines = append(lines, logicalLine{text: current.String(), number: start})
+	}
+	return lines, scanner.Err()
+}
+
+func trailingBackslashes(line string) int {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count
+}
+
+// splitPropertiesLine splits logical line into still escaped key and value.
+// Key ends at the first unescaped '=', ':' or whitespace.
+func splitPropertiesLine(line string) (string, string) {
+	keyEnd := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.ContainsRune("=: \t\f", rune(line[i])) {
+			keyEnd = i
+			break
+		}
+	}
+	rest := strings.TrimLeft(line[keyEnd:], " \t\f")
+	if strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":") {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return line[:keyEnd], rest
+}
+
+// unescapeProperties resolves escape sequences: \t, \n, \r, \f, \uXXXX;
+// backslash followed by any other character is replaced with the character.
+func unescapeProperties(str string) (string, error) {
+	if !strings.Contains(str, "\\") {
+		return str, nil
+	}
+	var builder strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] != '\\' || i == len(str)-1 {
+			builder.WriteByte(str[i])
+			continue
+		}
+		i++
+		switch str[i] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'u':
+			code, err := parseUnicodeEscape(str, i)
+			if err != nil {
+				return "", err
+			}
+			i += 4
+			// high surrogate followed by escaped low surrogate encodes single rune
+			if utf16.IsSurrogate(code) && strings.HasPrefix(str[i+1:], `\u`) {
+				if low, err := parseUnicodeEscape(str, i+2); err == nil {
+					if joined := utf16.DecodeRune(code, low); joined != unicode.ReplacementChar {
+						code = joined
+						i += 6
+					}
+				}
+			}
+			builder.WriteRune(code)
+		default:
+			builder.WriteByte(str[i])
+		}
+	}
+	return builder.String(), nil
+}
+
+// parseUnicodeEscape parses four hex digits following 'u' at index i of the string.
+func parseUnicodeEscape(str string, i int) (rune, error) {
+	if i+5 > len(str) {
+		return 0, fmt.Errorf("malformed \\uXXXX escape in %q", str)
+	}
+	code, err := strconv.ParseUint(str[i+1:i+5], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("malformed \\uXXXX escape in %q", str)
+	}
+	return rune(code), nil
+}
+
+// setNestedProp sets value of flat dotted key in the nested properties tree,
+// creating sections for each segment of the key. If some segment is already
+// occupied by a value, the rest of the key is kept as literal dotted key, e.g.
+// after 'a=1' the key 'a.b' is stored as the literal key 'a.b' next to 'a'.
+// Likewise a section replaced by a value is kept as literal dotted keys.
+// Both forms are resolved by getters the same way.
+func setNestedProp(props map[string]interface{}, key string, val interface{}) {
+	segments := strings.Split(key, ".")
+	current := props
+	for i, segment := range segments[:len(segments)-1] {
+		next, exists := current[segment]
+		if !exists {
+			submap := make(map[string]interface{})
+			current[segment] = submap
+			current = submap
+			continue
+		}
+		submap, ok := next.(map[string]interface{})
+		if !ok {
+			current[strings.Join(segments[i:], ".")] = val
+			return
+		}
+		current = submap
+	}
+	last := segments[len(segments)-1]
+	if submap, ok := current[last].(map[string]interface{}); ok {
+		if _, isMap := val.(map[string]interface{}); !isMap {
+			walkProps(last, submap, func(nestedKey string, nestedVal interface{}) {
+				current[nestedKey] = nestedVal
+			})
+		}
+	}
+	current[last] = val
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfig_Properties(t *testing.T) {
+	config := NewConfig("./test_config.properties", Properties)
+	testConfigPositiveCases(t, config)
+}
+
+func TestConfig_PropertiesEscapes(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.properties", Properties)
+
+	assert.Equal("value\twith\ttabs", config.GetString("escapes.key with spaces"))
+	assert.Equal("café", config.GetString("escapes.unicode"))
+	assert.Equal("a=b", config.GetString("escapes.equals=sign"))
+	assert.Equal("first, second, third", config.GetString("escapes.multiline"))
+	assert.True(config.IsSet("escapes.empty"))
+	assert.Equal("", config.GetString("escapes.empty", "default"))
+	assert.Equal(212.212, config.GetFloat64("subroot.family1.key2"))
+}
+
+func TestConfig_PropertiesMalformedEscape(t *testing.T) {
+	assert := assertions.New(t)
+
+	_, err := propertiesToJSON([]byte(`key=\u12`))
+	assert.NotNil(err)
+	_, err = propertiesToJSON([]byte(`key\uXYZW=1`))
+	assert.NotNil(err)
+}
+
+func TestSetNestedProp(t *testing.T) {
+	assert := assertions.New(t)
+
+	props := make(map[string]interface{})
+	setNestedProp(props, "a.b.c", "1")
+	setNestedProp(props, "a.b", "2")
+	setNestedProp(props, "a.b.d", "3")
+	setNestedProp(props, "x", "4")
+	setNestedProp(props, "x.y", "5")
+
+	assert.Equal(map[string]interface{}{
+		"a": map[string]interface{}{
+			"b":   "2",
+			"b.c": "1",
+			"b.d": "3",
+		},
+		"x":   "4",
+		"x.y": "5",
+	}, props)
+}
+
+func TestPropertiesSurrogatePairs(t *testing.T) {
+	assert := assertions.New(t)
+
+	escaped := escapeProperties("smile 😀", false)
+	assert.Equal(`smile \ud83d\ude00`, escaped)
+	unescaped, err := unescapeProperties(escaped)
+	assert.NoError(err)
+	assert.Equal("smile 😀", unescaped)
+
+	unescaped, err = unescapeProperties(`lone \ud83d and \u00e9`)
+	assert.NoError(err)
+	assert.Equal("lone � and é", unescaped)
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	// SourceFile means that the value was read from config file.
+	SourceFile = iota
+	// SourceEnv means that the value was read from environment variable.
+	SourceEnv
+	// SourceDefault means that the value is the default provided by the caller.
+	SourceDefault
+	// SourceDotenv means that the value was read from variable of .env file.
+	SourceDotenv
+	// SourceFlag means that the value was read from command-line flag or argument.
+	SourceFlag
+)
+
+// Source describes where the value of the property comes from.
+type Source struct {
+	// Kind is one of the constants: config.SourceFlag, config.SourceFile,
+	// config.SourceEnv, config.SourceDotenv or config.SourceDefault.
+	Kind int
+	// Key is the key the value was found by, differs from the requested key for aliases.
+	Key string
+	// Name is the config file path for SourceFile, env variable name for SourceEnv
+	// and SourceDotenv or the flag, e.g. '--db.host', for SourceFlag.
+	Name string
+	// File is the .env file path for SourceDotenv.
+	File string
+	// Line is the line number in config file or .env file, 0 if unknown.
+	Line int
+}
+
+// Candidate is the value offered by one of the sources.
+type Candidate struct {
+	Source Source
+	Value  interface{}
+}
+
+// Explanation describes how the property value is resolved.
+type Explanation struct {
+	// Key is the requested key.
+	Key string
+	// Value is the resolved value, nil if the property couldn't be resolved.
+	Value interface{}
+	// Source is the source of the resolved value, nil if the property couldn't be resolved.
+	Source *Source
+	// Shadowed lists values of other sources overridden by the resolved one,
+	// in order of precedence.
+	Shadowed []Candidate
+}
+
+// Explain describes how GetString and other getters resolve the property: the
+// resolved value, its source and all the values of other sources it shadows.
+// Sources are checked in the same order as by getters: command-line flags, config
+// file, environment variable or variable of .env file, then the defaultVal if
+// provided. Aliases are checked as well.
+func (c *Config) Explain(key string, defaultVal ...interface{}) Explanation {
+	var candidates []Candidate
+	keys := c.keyCandidates(key)
+	for _, candidate := range keys {
+		if val, found := c.lookupFlag(candidate); found {
+			candidates = append(candidates, Candidate{
+				Source: Source{Kind: SourceFlag, Key: candidate, Name: "--" + candidate},
+				Value:  c.expandProp(candidate, val, nil, true),
+			})
+		}
+	}
+	for _, candidate := range keys {
+		if prop := findPropInMap(candidate, c.props()); prop != nil {
+			candidates = append(candidates, Candidate{
+				Source: c.fileSource(candidate),
+				Value:  c.expandProp(candidate, prop, nil, true),
+			})
+		}
+	}
+	for _, candidate := range keys {
+		envName := envVarName(candidate)
+		envVar, ok := c.lookupEnvVar(envName)
+		if !ok {
+			continue
+		}
+		source := Source{Kind: SourceEnv, Key: candidate, Name: envName}
+		if envVar.file != "" {
+			source = Source{Kind: SourceDotenv, Key: candidate, Name: envName, File: envVar.file, Line: envVar.line}
+		}
+		candidates = append(candidates, Candidate{Source: source, Value: envVar.value})
+	}
+	if len(defaultVal) > 0 {
+		candidates = append(candidates, Candidate{
+			Source: Source{Kind: SourceDefault, Key: key},
+			Value:  defaultVal[0],
+		})
+	}
+
+	explanation := Explanation{Key: key}
+	if len(candidates) > 0 {
+		explanation.Value = candidates[0].Value
+		explanation.Source = &candidates[0].Source
+		explanation.Shadowed = candidates[1:]
+	}
+	return explanation
+}
+
+// fileSource returns the source of the property read from config file or from
+// the file of config directory.
+func (c *Config) fileSource(key string) Source {
+	c.propsMu.RLock()
+	defer c.propsMu.RUnlock()
+	// the longest prefix is the most specific source, e.g. file of 'db.host'
+	// rather than the one of the 'db' section
+	longest, name := -1, ""
+	for prefix, path := range c.keyFiles {
+		if _, ok := replaceKeyPrefix(key, prefix, ""); ok && len(prefix) > longest {
+			longest, name = len(prefix), path
+		}
+	}
+	if longest >= 0 {
+		return Source{Kind: SourceFile, Key: key, Name: name}
+	}
+	return Source{Kind: SourceFile, Key: key, Name: c.filePath, Line: c.lines[key]}
+}
+
+// String formats the source in human-readable form, e.g. 'file ./config.yaml:12'.
+func (s Source) String() string {
+	switch s.Kind {
+	case SourceFile:
+		if s.Line > 0 {
+			return fmt.Sprintf("file %s:%d", s.Name, s.Line)
+		}
+		return "file " + s.Name
+	case SourceEnv:
+		return "env " + s.Name
+	case SourceDotenv:
+		return fmt.Sprintf("env %s from %s:%d", s.Name, s.File, s.Line)
+	case SourceFlag:
+		return "flag " + s.Name
+	default:
+		return "default"
+	}
+}
+
+// String formats the explanation in human-readable form.
+func (e Explanation) String() string {
+	if e.Source == nil {
+		return fmt.Sprintf("%s is not set", e.Key)
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%s = %v (from %s)", e.Key, e.Value, e.Source))
+	for _, shadowed := range e.Shadowed {
+		builder.WriteString(fmt.Sprintf("\n  shadows %v (from %s)", shadowed.Value, shadowed.Source))
+	}
+	return builder.String()
+}
+
+// indexLines maps fully qualified keys of the parsed YAML or JSON document to
+// line numbers. Line numbers stay unknown for other formats.
+func (c *Config) indexLines(root *yamlv3.Node) {
+	if root == nil {
+		return
+	}
+	c.lines = make(map[string]int)
+	c.indexNode("", root)
+}
+
+func (c *Config) indexNode(prefix string, node *yamlv3.Node) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name, err := yamlMapKey(node.Content[i])
+			if err != nil {
+				name = node.Content[i].Value
+			}
+			key := joinKey(prefix, c.normalizeKey(name))
+			c.lines[key] = node.Content[i].Line
+			c.indexNode(key, node.Content[i+1])
+		}
+	case yamlv3.SequenceNode:
+		for i, elem := range node.Content {
+			key := fmt.Sprintf("%s[%d]", prefix, i)
+			c.lines[key] = elem.Line
+			c.indexNode(key, elem)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestConfig_Explain(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.yaml", Yaml)
+
+	explanation := config.Explain("root.family1.key2.subkey1", "default_val")
+	assert.Equal("test121", explanation.Value)
+	assert.Equal(&Source{Kind: SourceFile, Key: "root.family1.key2.subkey1", Name: "./test_config.yaml", Line: 4},
+		explanation.Source)
+	assert.Equal([]Candidate{
+		{Source: Source{Kind: SourceDefault, Key: "root.family1.key2.subkey1"}, Value: "default_val"},
+	}, explanation.Shadowed)
+	assert.Equal("root.family1.key2.subkey1 = test121 (from file ./test_config.yaml:4)\n"+
+		"  shadows default_val (from default)", explanation.String())
+
+	explanation = config.Explain("another.simple.prop")
+	assert.Equal(float64(4), explanation.Value)
+	assert.Equal("file ./test_config.yaml:20", explanation.Source.String())
+	assert.Empty(explanation.Shadowed)
+
+	explanation = config.Explain("missing.property")
+	assert.Nil(explanation.Value)
+	assert.Nil(explanation.Source)
+	assert.Equal("missing.property is not set", explanation.String())
+}
+
+func TestConfig_ExplainEnvs(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.json", Json)
+	config.RegisterAlias("root.family1.old_key", "root.family1.key1")
+
+	err := os.Setenv("ROOT_FAMILY1_KEY1", "env_val")
+	assert.Nil(err)
+	err = os.Setenv("ROOT_FAMILY1_OLD_KEY", "old_env_val")
+	assert.Nil(err)
+	err = os.Setenv("TEST_EXPLAIN_KEY", "env_only")
+	assert.Nil(err)
+
+	explanation := config.Explain("root.family1.old_key", "default_val")
+	assert.Equal("test11", explanation.Value)
+	assert.Equal("file ./test_config.json:4", explanation.Source.String())
+	assert.Equal("root.family1.key1", explanation.Source.Key)
+	assert.Equal([]Candidate{
+		{Source: Source{Kind: SourceEnv, Key: "root.family1.key1", Name: "ROOT_FAMILY1_KEY1"}, Value: "env_val"},
+		{Source: Source{Kind: SourceEnv, Key: "root.family1.old_key", Name: "ROOT_FAMILY1_OLD_KEY"}, Value: "old_env_val"},
+		{Source: Source{Kind: SourceDefault, Key: "root.family1.old_key"}, Value: "default_val"},
+	}, explanation.Shadowed)
+
+	explanation = config.Explain("test.explain.key", 5)
+	assert.Equal("env_only", explanation.Value)
+	assert.Equal("env TEST_EXPLAIN_KEY", explanation.Source.String())
+	assert.Equal([]Candidate{{Source: Source{Kind: SourceDefault, Key: "test.explain.key"}, Value: 5}},
+		explanation.Shadowed)
+
+	err = os.Unsetenv("ROOT_FAMILY1_KEY1")
+	assert.Nil(err)
+	err = os.Unsetenv("ROOT_FAMILY1_OLD_KEY")
+	assert.Nil(err)
+	err = os.Unsetenv("TEST_EXPLAIN_KEY")
+	assert.Nil(err)
+}
+
+func TestConfig_ExplainLists(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_lists.yaml", Yaml)
+	assert.Equal("file ./test_config_lists.yaml:7", config.Explain("servers[2].host").Source.String())
+	assert.Equal("file ./test_config_lists.yaml", config.Explain("servers[-1].host").Source.String())
+}
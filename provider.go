@@ -0,0 +1,374 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider is a source of properties, e.g. a key-value store like etcd or Consul.
+type Provider interface {
+	// Load returns the properties tree. Nested sections are represented with
+	// map[string]interface{}, see PropertiesFromPaths for key-value stores.
+	Load(ctx context.Context) (map[string]interface{}, error)
+	// Watch returns channel receiving the events on every change of properties.
+	// The channel is closed when the context is done.
+	Watch(ctx context.Context) <-chan Event
+}
+
+// Event describes the change of the property of Provider.
+type Event struct {
+	// Key is the dotted key of the changed property.
+	Key string
+	// Value is the new value of the property, empty if the property was deleted.
+	Value string
+	// Deleted is true if the property was deleted.
+	Deleted bool
+}
+
+// WithProviders adds providers as sources of properties. Properties of providers
+// take precedence over config file and directories, providers passed later take
+// precedence over earlier ones. NewConfig panics if provider fails to load.
+// See also WatchProviders.
+func WithProviders(providers ...Provider) Option {
+	return func(c *Config) {
+		c.providers = append(c.providers, providers...)
+	}
+}
+
+// NewProviderConfig builds Config reading properties from providers only,
+// without config file. See WithProviders.
+func NewProviderConfig(providers []Provider, opts ...Option) *Config {
+	configHolder := Config{logger: log.Default(), providers: providers}
+	for _, opt := range opts {
+		opt(&configHolder)
+	}
+	configHolder.setLoaded(configHolder.load(context.Background()))
+	return &configHolder
+}
+
+// WatchProviders watches all the providers and calls Reload on their events
+// until the context is done. Events received while reload is in progress are
+// batched into single next reload, so burst of changes doesn't cause reload
+// per key. Reload errors are logged.
+func (c *Config) WatchProviders(ctx context.Context) {
+	changed := make(chan struct{}, 1)
+	for _, provider := range c.providers {
+		go func(events <-chan Event) {
+			for range events {
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}(provider.Watch(ctx))
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+			if err := c.ReloadContext(ctx); err != nil && ctx.Err() == nil {
+				c.logger.Printf("Failed to reload config: %v", err)
+			}
+		}
+	}()
+}
+
+// PropertiesFromPaths converts key-value pairs with slash-separated paths as
+// keys into the properties tree, e.g. value of 'app/db/host' is resolved by the
+// key 'app.db.host'. Leading and trailing slashes are ignored.
+func PropertiesFromPaths(values map[string]string) map[string]interface{} {
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	props := make(map[string]interface{})
+	for _, path := range paths {
+		setNestedProp(props, pathToKey(path), values[path])
+	}
+	return props
+}
+
+func pathToKey(path string) string {
+	return strings.ReplaceAll(strings.Trim(path, "/"), "/", ".")
+}
+
+// providerName describes the provider in source descriptions and errors.
+func providerName(provider Provider) string {
+	if stringer, ok := provider.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T", provider)
+}
+
+// MemoryProvider is in-memory key-value store with slash-separated paths as keys.
+// It is safe for concurrent use.
+type MemoryProvider struct {
+	mu       sync.Mutex
+	values   map[string]string
+	watchers []*memoryWatcher
+}
+
+// memoryWatcher queues events of MemoryProvider until the watcher receives them.
+type memoryWatcher struct {
+	events  chan Event
+	wake    chan struct{}
+	pending []Event
+}
+
+// NewMemoryProvider creates MemoryProvider holding the values.
+func NewMemoryProvider(values map[string]string) *MemoryProvider {
+	provider := &MemoryProvider{values: make(map[string]string, len(values))}
+	for path, val := range values {
+		provider.values[path] = val
+	}
+	return provider
+}
+
+// Load returns properties tree built from the stored values.
+func (p *MemoryProvider) Load(ctx context.Context) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PropertiesFromPaths(p.values), nil
+}
+
+// Watch returns channel receiving the events of Set and Delete, see notify.
+func (p *MemoryProvider) Watch(ctx context.Context) <-chan Event {
+	watcher := &memoryWatcher{events: make(chan Event), wake: make(chan struct{}, 1)}
+	p.mu.Lock()
+	p.watchers = append(p.watchers, watcher)
+	p.mu.Unlock()
+	go func() {
+		defer close(watcher.events)
+		defer p.removeWatcher(watcher)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-watcher.wake:
+			}
+			p.mu.Lock()
+			batch := watcher.pending
+			watcher.pending = nil
+			p.mu.Unlock()
+			for _, event := range batch {
+				select {
+				case watcher.events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return watcher.events
+}
+
+func (p *MemoryProvider) removeWatcher(watcher *memoryWatcher) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, registered := range p.watchers {
+		if registered == watcher {
+			p.watchers = append(p.watchers[:i], p.watchers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Set stores the value by the path and notifies watchers.
+func (p *MemoryProvider) Set(path, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values[path] = value
+	p.notify(Event{Key: pathToKey(path), Value: value})
+}
+
+// Delete removes the value by the path and notifies watchers.
+func (p *MemoryProvider) Delete(path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.values[path]; !ok {
+		return
+	}
+	delete(p.values, path)
+	p.notify(Event{Key: pathToKey(path), Deleted: true})
+}
+
+func (p *MemoryProvider) String() string {
+	return "memory provider"
+}
+
+// notify queues the event for every watcher without blocking, so watchers
+// reloading properties from the provider on events don't deadlock. Pending
+// event of the same key is replaced with the new one, so slow watcher receives
+// the latest change of every key and the queue is bounded by the number of keys.
+func (p *MemoryProvider) notify(event Event) {
+	for _, watcher := range p.watchers {
+		for i, pending := range watcher.pending {
+			if pending.Key == event.Key {
+				watcher.pending = append(watcher.pending[:i], watcher.pending[i+1:]...)
+				break
+			}
+		}
+		watcher.pending = append(watcher.pending, event)
+		select {
+		case watcher.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// FileProvider is key-value store persisted to JSON file holding an object with
+// slash-separated paths as keys and string values. Changes made by other
+// processes are detected by polling the modification time of the file.
+type FileProvider struct {
+	path     string
+	interval time.Duration
+	mu       sync.Mutex
+}
+
+// NewFileProvider creates FileProvider for the file, which is created on the
+// first Set if missing. Watch checks the file for changes every interval.
+func NewFileProvider(path string, interval time.Duration) *FileProvider {
+	return &FileProvider{path: path, interval: interval}
+}
+
+// Load returns properties tree built from the values of the file.
+// Missing file is loaded as empty store.
+func (p *FileProvider) Load(ctx context.Context) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	values, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	return PropertiesFromPaths(values), nil
+}
+
+// Watch returns channel receiving the events for every value changed in the file.
+func (p *FileProvider) Watch(ctx context.Context) <-chan Event {
+	return pollEvents(ctx, p.interval, p.Load)
+}
+
+// Set stores the value by the path to the file.
+func (p *FileProvider) Set(path, value string) error {
+	return p.update(func(values map[string]string) {
+		values[path] = value
+	})
+}
+
+// Delete removes the value by the path from the file.
+func (p *FileProvider) Delete(path string) error {
+	return p.update(func(values map[string]string) {
+		delete(values, path)
+	})
+}
+
+func (p *FileProvider) String() string {
+	return "file provider " + p.path
+}
+
+func (p *FileProvider) update(change func(values map[string]string)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	values, err := p.read()
+	if err != nil {
+		return err
+	}
+	change(values)
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(p.path, data)
+}
+
+func (p *FileProvider) read() (map[string]string, error) {
+	values := make(map[string]string)
+	data, err := ioutil.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", p.path, err)
+	}
+	return values, nil
+}
+
+// pollEvents loads properties every interval and sends events for the changed
+// ones until the context is done. Polls failed to load are skipped.
+func pollEvents(ctx context.Context, interval time.Duration, load func(ctx context.Context) (map[string]interface{}, error)) <-chan Event {
+	events := make(chan Event)
+	last, _ := load(ctx)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current, err := load(ctx)
+			if err != nil {
+				continue
+			}
+			for _, event := range diffProps(last, current) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			last = current
+		}
+	}()
+	return events
+}
+
+// diffProps returns events for the properties changed between the snapshots, sorted by key.
+func diffProps(old, current map[string]interface{}) []Event {
+	flatten := func(props map[string]interface{}) map[string]string {
+		values := make(map[string]string)
+		walkProps("", props, func(key string, val interface{}) {
+			values[key] = fmt.Sprint(val)
+		})
+		return values
+	}
+	oldValues, currentValues := flatten(old), flatten(current)
+	var events []Event
+	for key, val := range currentValues {
+		if oldVal, ok := oldValues[key]; !ok || oldVal != val {
+			events = append(events, Event{Key: key, Value: val})
+		}
+	}
+	for key := range oldValues {
+		if _, ok := currentValues[key]; !ok {
+			events = append(events, Event{Key: key, Deleted: true})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Key < events[j].Key
+	})
+	return events
+}
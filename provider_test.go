@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	assertions "github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPropertiesFromPaths(t *testing.T) {
+	assert := assertions.New(t)
+
+	props := PropertiesFromPaths(map[string]string{
+		"/app/db/host": "localhost",
+		"app/db/port":  "5432",
+		"app/name/":    "demo",
+	})
+	assert.Equal(map[string]interface{}{
+		"app": map[string]interface{}{
+			"db":   map[string]interface{}{"host": "localhost", "port": "5432"},
+			"name": "demo",
+		},
+	}, props)
+}
+
+func TestConfig_WithProviders(t *testing.T) {
+	assert := assertions.New(t)
+	base := NewMemoryProvider(map[string]string{
+		"root/family1/key1": "from-memory",
+		"db/host":           "memory-host",
+	})
+	override := NewFileProvider(filepath.Join(t.TempDir(), "kv.json"), 5*time.Millisecond)
+	assert.NoError(override.Set("db/host", "file-host"))
+
+	config := NewConfig("./test_config.yaml", Yaml, WithProviders(base, override))
+	assert.Equal("from-memory", config.GetString("root.family1.key1"))
+	assert.Equal("test2", config.GetString("root.family2"))
+	assert.Equal("file-host", config.GetString("db.host"))
+	assert.Equal("file provider "+override.path, config.Explain("db.host").Source.Name)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config.WatchProviders(ctx)
+
+	base.Set("db/port", "5432")
+	assert.Eventually(func() bool {
+		return config.GetInt("db.port") == 5432
+	}, time.Second, 5*time.Millisecond)
+
+	assert.NoError(override.Delete("db/host"))
+	assert.Eventually(func() bool {
+		return config.GetString("db.host") == "memory-host"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestMemoryProvider_Watch(t *testing.T) {
+	assert := assertions.New(t)
+	provider := NewMemoryProvider(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	events := provider.Watch(ctx)
+
+	provider.Set("app/name", "demo")
+	assert.Equal(Event{Key: "app.name", Value: "demo"}, <-events)
+	provider.Delete("app/missing")
+	provider.Delete("app/name")
+	assert.Equal(Event{Key: "app.name", Deleted: true}, <-events)
+
+	cancel()
+	_, open := <-events
+	assert.False(open)
+}
+
+func TestMemoryProvider_WatchBurst(t *testing.T) {
+	assert := assertions.New(t)
+	provider := NewMemoryProvider(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := provider.Watch(ctx)
+
+	for i := 1; i <= 100; i++ {
+		provider.Set("app/counter", strconv.Itoa(i))
+		provider.Set(fmt.Sprintf("app/keys/%d", i), "x")
+	}
+	provider.Set("app/done", "true")
+
+	var counters []string
+	keys := 0
+	for event := range events {
+		if event.Key == "app.done" {
+			break
+		}
+		if event.Key == "app.counter" {
+			counters = append(counters, event.Value)
+		} else {
+			keys++
+		}
+	}
+	assert.Equal(100, keys)
+	assert.LessOrEqual(len(counters), 2)
+	assert.Equal("100", counters[len(counters)-1])
+}
+
+// countingProvider counts loads of the wrapped provider.
+type countingProvider struct {
+	Provider
+	loads int32
+}
+
+func (p *countingProvider) Load(ctx context.Context) (map[string]interface{}, error) {
+	atomic.AddInt32(&p.loads, 1)
+	return p.Provider.Load(ctx)
+}
+
+func TestConfig_WatchProvidersBatches(t *testing.T) {
+	assert := assertions.New(t)
+	provider := &countingProvider{Provider: NewMemoryProvider(nil)}
+	config := NewProviderConfig([]Provider{provider})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config.WatchProviders(ctx)
+
+	memory := provider.Provider.(*MemoryProvider)
+	for i := 0; i < 100; i++ {
+		memory.Set(fmt.Sprintf("app/key%d", i), strconv.Itoa(i))
+	}
+	assert.Eventually(func() bool {
+		return config.GetInt("app.key99") == 99
+	}, time.Second, 5*time.Millisecond)
+	assert.Less(atomic.LoadInt32(&provider.loads), int32(50))
+}
+
+func TestFileProvider_Watch(t *testing.T) {
+	assert := assertions.New(t)
+	path := filepath.Join(t.TempDir(), "kv.json")
+	provider := NewFileProvider(path, 5*time.Millisecond)
+	assert.NoError(provider.Set("app/name", "demo"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := provider.Watch(ctx)
+
+	writer := NewFileProvider(path, time.Second)
+	assert.NoError(writer.Set("app/port", "8080"))
+	assert.Equal(Event{Key: "app.port", Value: "8080"}, <-events)
+
+	props, err := provider.Load(ctx)
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{"app": map[string]interface{}{"name": "demo", "port": "8080"}}, props)
+
+	cancel()
+	_, err = provider.Load(ctx)
+	assert.ErrorIs(err, context.Canceled)
+}
+
+func TestNewProviderConfig(t *testing.T) {
+	assert := assertions.New(t)
+	config := NewProviderConfig([]Provider{NewMemoryProvider(map[string]string{"app/debug": "true"})})
+	assert.True(config.GetBool("app.debug"))
+	assert.Equal([]string{"app.debug"}, config.AllKeys())
+}
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRemoteTimeout = 10 * time.Second
+	defaultRemoteRetries = 3
+	defaultRemoteBackoff = 500 * time.Millisecond
+)
+
+// remoteOptions are the settings of remote requests collected from options,
+// they take effect only for remote configs.
+type remoteOptions struct {
+	client    *http.Client
+	timeout   time.Duration
+	retries   int
+	backoff   time.Duration
+	cacheFile string
+}
+
+type remoteSource struct {
+	remoteOptions
+	url string
+
+	mu   sync.Mutex
+	etag string
+	body []byte
+}
+
+// NewRemoteConfig builds Config reading the document from the URL with HTTP
+// GET request. Argument format is the same as for NewConfig, config.Auto detects
+// the format by extension of the URL path. Include directives are not resolved
+// for remote documents.
+//
+// Failed requests are retried with exponential backoff, see WithRemoteRetries.
+// If the server is unreachable, the last known good document is read from the
+// cache file set with WithRemoteCache. NewRemoteConfig panics if the document
+// can be read neither from the server nor from the cache.
+//
+// Reload requests the document again with If-None-Match header holding the
+// ETag of the last response, so WatchReload polls the server cheaply.
+func NewRemoteConfig(rawURL string, format int, opts ...Option) *Config {
+	configHolder := Config{filePath: rawURL, format: format, logger: log.Default()}
+	for _, opt := range opts {
+		opt(&configHolder)
+	}
+	configHolder.remote = newRemoteSource(rawURL, configHolder.remoteOptions())
+	if format == Auto {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			log.Panicf("Failed to parse config URL: %v", err)
+		}
+		configHolder.format = resolveFormat(Auto, parsed.Path)
+	}
+	configHolder.setLoaded(configHolder.load(context.Background()))
+	return &configHolder
+}
+
+// WithRemoteTimeout sets timeout of every request of NewRemoteConfig, 10 seconds by default.
+// Remote options have no effect on configs built with NewConfig.
+func WithRemoteTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.remoteOptions().timeout = timeout
+	}
+}
+
+// WithRemoteRetries sets the number of retries of failed requests of NewRemoteConfig
+// and the delay before the first retry, which is doubled for every next retry.
+// Requests failed with network errors and 5xx status codes are retried.
+// By default requests are retried 3 times starting with 500ms delay.
+func WithRemoteRetries(retries int, backoff time.Duration) Option {
+	return func(c *Config) {
+		remote := c.remoteOptions()
+		remote.retries = retries
+		remote.backoff = backoff
+	}
+}
+
+// WithRemoteCache makes NewRemoteConfig store every document received from
+// the server to the file and read it from there if the server is unreachable.
+// Failure to write the file is logged as warning, the document is still used.
+func WithRemoteCache(path string) Option {
+	return func(c *Config) {
+		c.remoteOptions().cacheFile = path
+	}
+}
+
+// WithHTTPClient sets the client for requests of NewRemoteConfig, e.g. to
+// configure TLS or authentication. Timeout of the client is overridden by
+// WithRemoteTimeout if both are set.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Config) {
+		c.remoteOptions().client = client
+	}
+}
+
+// remoteOptions returns the settings of remote requests, creating the default ones.
+func (c *Config) remoteOptions() *remoteOptions {
+	if c.remoteOpts == nil {
+		c.remoteOpts = &remoteOptions{timeout: defaultRemoteTimeout, retries: defaultRemoteRetries, backoff: defaultRemoteBackoff}
+	}
+	return c.remoteOpts
+}
+
+func newRemoteSource(rawURL string, opts *remoteOptions) *remoteSource {
+	remote := &remoteSource{remoteOptions: *opts, url: rawURL}
+	if remote.client == nil {
+		remote.client = &http.Client{}
+	}
+	return remote
+}
+
+// fetch returns the document from the server, retrying failed requests.
+// If the document wasn't modified since the last request, the last one is
+// returned. On failure the document is read from cache file if there is no
+// document received earlier. Requests and backoff stop when the context is
+// done, the context error is returned then.
+func (r *remoteSource) fetch(ctx context.Context, logger Logger) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var err error
+	for attempt := 0; attempt <= r.retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(r.backoff << (attempt - 1))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+		}
+		var body []byte
+		var retry bool
+		if body, retry, err = r.request(ctx, logger); err == nil {
+			return body, nil
+		}
+		if !retry || ctx.Err() != nil {
+			break
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if r.body != nil || r.cacheFile == "" {
+		return nil, err
+	}
+	cached, cacheErr := ioutil.ReadFile(r.cacheFile)
+	if cacheErr != nil {
+		return nil, fmt.Errorf("%w (cache: %v)", err, cacheErr)
+	}
+	logger.Printf("Warning: failed to fetch config %s, using cached copy %s: %v", r.url, r.cacheFile, err)
+	return cached, nil
+}
+
+// request performs single GET request. It reports whether the failed request
+// may be retried. Failure to write the cache file is logged as warning.
+func (r *remoteSource) request(ctx context.Context, logger Logger) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if r.etag != "" && r.body != nil {
+		req.Header.Set("If-None-Match", r.etag)
+	}
+	client := *r.client
+	if r.timeout > 0 {
+		client.Timeout = r.timeout
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && r.body != nil:
+		return r.body, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, resp.StatusCode >= 500, fmt.Errorf("GET %s: unexpected status %s", r.url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, err
+	}
+	r.etag = resp.Header.Get("ETag")
+	r.body = body
+	if r.cacheFile != "" {
+		if err := writeFileAtomically(r.cacheFile, body); err != nil {
+			logger.Printf("Warning: failed to write config cache %s: %v", r.cacheFile, err)
+		}
+	}
+	return body, false, nil
+}
+
+// writeFileAtomically writes the file via temporary file, so readers never see
+// partially written contents.
+func writeFileAtomically(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+type remoteProvider struct {
+	remote *remoteSource
+	format int
+	logger Logger
+}
+
+// NewRemoteSource creates Provider reading the document from the URL the same
+// way as NewRemoteConfig does, to be used with LoadContext or WithProviders.
+// Argument format is the same as for NewConfig, remote options and WithLogger
+// are applied, other options are ignored. Requests and retries are canceled
+// when the context of Load is done. Watch polls the server every second.
+func NewRemoteSource(rawURL string, format int, opts ...Option) Provider {
+	settings := Config{logger: log.Default()}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	return &remoteProvider{
+		remote: newRemoteSource(rawURL, settings.remoteOptions()),
+		format: format,
+		logger: settings.logger,
+	}
+}
+
+// Load fetches and parses the document.
+func (p *remoteProvider) Load(ctx context.Context) (map[string]interface{}, error) {
+	format := p.format
+	if format == Auto {
+		parsed, err := url.Parse(p.remote.url)
+		if err != nil {
+			return nil, err
+		}
+		detected, ok := FormatByExtension(parsed.Path)
+		if !ok {
+			return nil, fmt.Errorf("unknown config file extension: %s", p.remote.url)
+		}
+		format = detected
+	} else if getFormat(format) == nil {
+		return nil, fmt.Errorf("unknown config format: %v", format)
+	}
+	data, err := p.remote.fetch(ctx, p.logger)
+	if err != nil {
+		return nil, err
+	}
+	return parseWithFormat(format, data)
+}
+
+// Watch returns channel receiving the events for every property changed in the document.
+func (p *remoteProvider) Watch(ctx context.Context) <-chan Event {
+	return pollEvents(ctx, sourcePollInterval, p.Load)
+}
+
+func (p *remoteProvider) String() string {
+	return "remote " + p.remote.url
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	assertions "github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// configServer serves YAML document with ETag and counts requests.
+type configServer struct {
+	mu       sync.Mutex
+	body     string
+	version  int
+	failures int
+	requests int
+	notMod   int
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	etag := fmt.Sprintf(`"v%d"`, s.version)
+	if r.Header.Get("If-None-Match") == etag {
+		s.notMod++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write([]byte(s.body))
+}
+
+func (s *configServer) update(body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+	s.version++
+}
+
+func TestNewRemoteConfig(t *testing.T) {
+	assert := assertions.New(t)
+	data, err := ioutil.ReadFile("./test_config.yaml")
+	assert.NoError(err)
+	handler := &configServer{body: string(data), failures: 2}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	config := NewRemoteConfig(server.URL+"/config.yaml?env=test", Auto, WithRemoteRetries(2, time.Millisecond))
+	testConfigPositiveCases(t, config)
+	assert.Equal(3, handler.requests)
+	source := config.Explain("root.family1.key1").Source
+	assert.Equal(server.URL+"/config.yaml?env=test", source.Name)
+	assert.Equal(3, source.Line)
+
+	assert.NoError(config.Reload())
+	assert.Equal(1, handler.notMod)
+	assert.Equal("test11", config.GetString("root.family1.key1"))
+
+	handler.update("root:\n  family1:\n    key1: updated\n")
+	stop := config.WatchReload(5 * time.Millisecond)
+	defer stop()
+	assert.Eventually(func() bool {
+		return config.GetString("root.family1.key1") == "updated"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestNewRemoteConfig_Cache(t *testing.T) {
+	assert := assertions.New(t)
+	cacheFile := filepath.Join(t.TempDir(), "config.json")
+	handler := &configServer{body: `{"db": {"host": "remote"}}`}
+	server := httptest.NewServer(handler)
+
+	config := NewRemoteConfig(server.URL, Json, WithRemoteCache(cacheFile),
+		WithRemoteTimeout(time.Second), WithRemoteRetries(1, time.Millisecond))
+	assert.Equal("remote", config.GetString("db.host"))
+	server.Close()
+
+	// the server is down: reload keeps properties, new config reads the cache
+	assert.Error(config.Reload())
+	assert.Equal("remote", config.GetString("db.host"))
+
+	logger := &testLogger{}
+	cached := NewRemoteConfig(server.URL, Json, WithRemoteCache(cacheFile),
+		WithRemoteRetries(1, time.Millisecond), WithLogger(logger))
+	assert.Equal("remote", cached.GetString("db.host"))
+	assert.Len(logger.messages, 1)
+	assert.Contains(logger.messages[0], "using cached copy "+cacheFile)
+}
+
+func TestNewRemoteConfig_CacheWriteError(t *testing.T) {
+	assert := assertions.New(t)
+	handler := &configServer{body: `{"db": {"host": "remote"}}`}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	logger := &testLogger{}
+	cacheFile := filepath.Join(t.TempDir(), "missing", "config.json")
+	config := NewRemoteConfig(server.URL, Json, WithRemoteCache(cacheFile), WithLogger(logger))
+	assert.Equal("remote", config.GetString("db.host"))
+	assert.Len(logger.messages, 1)
+	assert.Contains(logger.messages[0], "Warning: failed to write config cache "+cacheFile)
+
+	assert.NoError(config.Reload())
+	assert.Equal(1, handler.notMod)
+}
+
+func TestNewRemoteConfig_Errors(t *testing.T) {
+	assert := assertions.New(t)
+	handler := http.NotFoundHandler()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	defer func() {
+		r := recover()
+		assert.NotNil(r)
+		assert.Contains(fmt.Sprint(r), "404 Not Found")
+	}()
+	_ = NewRemoteConfig(server.URL+"/config.json", Auto, WithRemoteRetries(3, time.Hour))
+}
+
+func TestNewConfig_RemoteOptions(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.yaml", Yaml, WithRemoteTimeout(time.Second),
+		WithRemoteRetries(1, time.Millisecond), WithHTTPClient(&http.Client{}))
+	assert.Equal("test11", config.GetString("root.family1.key1"))
+	assert.NoError(config.Reload())
+	assert.Equal("test11", config.GetString("root.family1.key1"))
+}
@@ -0,0 +1,337 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Schema is a subset of JSON Schema (draft 2020-12) used to validate the
+// properties tree. Supported keywords are type, properties, required, enum,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, minLength, maxLength,
+// minItems, maxItems, pattern, items and additionalProperties. Other keywords
+// are ignored. Boolean schemas true and false are supported as well, schema
+// false is built in Go code as &Schema{RejectAll: true}.
+type Schema struct {
+	Type                 []string
+	Properties           map[string]*Schema
+	Required             []string
+	Enum                 []interface{}
+	Minimum              *float64
+	Maximum              *float64
+	ExclusiveMinimum     *float64
+	ExclusiveMaximum     *float64
+	MinLength            *int
+	MaxLength            *int
+	MinItems             *int
+	MaxItems             *int
+	Pattern              string
+	Items                *Schema
+	AdditionalProperties *Schema
+	// RejectAll is set for boolean schema false that no value is valid against.
+	RejectAll bool
+
+	// pattern is Pattern compiled on parsing, schemas built in Go code have
+	// their pattern compiled on validation.
+	pattern *regexp.Regexp
+}
+
+// SchemaViolation is a single mismatch between the properties tree and the schema.
+type SchemaViolation struct {
+	// Pointer is JSON pointer to the invalid value, e.g. '/root/family1/key1'.
+	Pointer string
+	// Message describes the violation.
+	Message string
+}
+
+// SchemaError is returned from ValidateSchema and holds all violations found.
+type SchemaError []SchemaViolation
+
+// ParseSchema parses schema from JSON or YAML document.
+func ParseSchema(data []byte) (*Schema, error) {
+	plane, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert schema to json: %w", err)
+	}
+	var schema Schema
+	if err := json.Unmarshal(plane, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// LoadSchema reads schema in JSON or YAML format from the file.
+func LoadSchema(filePath string) (*Schema, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	return ParseSchema(data)
+}
+
+// WithSchema makes NewConfig validate loaded properties against the schema.
+// NewConfig panics listing all violations if the properties are invalid.
+func WithSchema(schema *Schema) Option {
+	return func(c *Config) {
+		c.schema = schema
+	}
+}
+
+// ValidateSchema validates properties read from file against the schema.
+// It returns SchemaError with all violations found or nil if properties are valid.
+func (c *Config) ValidateSchema(schema *Schema) error {
+	var violations SchemaError
+	schema.validate("", c.props(), &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+	return violations
+}
+
+func (c *Config) checkSchema() {
+	if c.schema == nil {
+		return
+	}
+	if err := c.ValidateSchema(c.schema); err != nil {
+		log.Panicf("Config file doesn't match the schema: %v", err)
+	}
+}
+
+func (e SchemaError) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, violation := range e {
+		messages = append(messages, violation.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// String formats the violation in human-readable form.
+func (v SchemaViolation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return pointer + ": " + v.Message
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting boolean schemas and
+// both string and array forms of the type keyword.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.Equal(trimmed, []byte("true")) {
+		*s = Schema{}
+		return nil
+	}
+	if bytes.Equal(trimmed, []byte("false")) {
+		*s = Schema{RejectAll: true}
+		return nil
+	}
+	var raw struct {
+		Type                 json.RawMessage    `json:"type"`
+		Properties           map[string]*Schema `json:"properties"`
+		Required             []string           `json:"required"`
+		Enum                 []interface{}      `json:"enum"`
+		Minimum              *float64           `json:"minimum"`
+		Maximum              *float64           `json:"maximum"`
+		ExclusiveMinimum     *float64           `json:"exclusiveMinimum"`
+		ExclusiveMaximum     *float64           `json:"exclusiveMaximum"`
+		MinLength            *int               `json:"minLength"`
+		MaxLength            *int               `json:"maxLength"`
+		MinItems             *int               `json:"minItems"`
+		MaxItems             *int               `json:"maxItems"`
+		Pattern              string             `json:"pattern"`
+		Items                *Schema            `json:"items"`
+		AdditionalProperties *Schema            `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = Schema{
+		Properties:           raw.Properties,
+		Required:             raw.Required,
+		Enum:                 raw.Enum,
+		Minimum:              raw.Minimum,
+		Maximum:              raw.Maximum,
+		ExclusiveMinimum:     raw.ExclusiveMinimum,
+		ExclusiveMaximum:     raw.ExclusiveMaximum,
+		MinLength:            raw.MinLength,
+		MaxLength:            raw.MaxLength,
+		MinItems:             raw.MinItems,
+		MaxItems:             raw.MaxItems,
+		Pattern:              raw.Pattern,
+		Items:                raw.Items,
+		AdditionalProperties: raw.AdditionalProperties,
+	}
+	if len(raw.Type) > 0 {
+		var single string
+		if err := json.Unmarshal(raw.Type, &single); err == nil {
+			s.Type = []string{single}
+		} else if err := json.Unmarshal(raw.Type, &s.Type); err != nil {
+			return fmt.Errorf("invalid type keyword: %s", raw.Type)
+		}
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = pattern
+	}
+	return nil
+}
+
+func (s *Schema) validate(pointer string, val interface{}, violations *SchemaError) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.RejectAll {
+		report("value is not allowed")
+		return
+	}
+	if len(s.Type) > 0 && !matchesAnyType(val, s.Type) {
+		report("expected type %s, got %s", strings.Join(s.Type, " or "), jsonType(val))
+		return
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, val) {
+		report("value %v is not one of %v", val, s.Enum)
+	}
+
+	switch typed := val.(type) {
+	case float64:
+		s.validateNumber(typed, report)
+	case string:
+		if s.MinLength != nil && len([]rune(typed)) < *s.MinLength {
+			report("length %d is less than minLength %d", len([]rune(typed)), *s.MinLength)
+		}
+		if s.MaxLength != nil && len([]rune(typed)) > *s.MaxLength {
+			report("length %d is greater than maxLength %d", len([]rune(typed)), *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			pattern, err := s.compiledPattern()
+			if err != nil {
+				report("invalid pattern %q: %v", s.Pattern, err)
+			} else if !pattern.MatchString(typed) {
+				report("value %q doesn't match pattern %q", typed, s.Pattern)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(typed) < *s.MinItems {
+			report("%d items is less than minItems %d", len(typed), *s.MinItems)
+		}
+		if s.MaxItems != nil && len(typed) > *s.MaxItems {
+			report("%d items is greater than maxItems %d", len(typed), *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, elem := range typed {
+				s.Items.validate(fmt.Sprintf("%s/%d", pointer, i), elem, violations)
+			}
+		}
+	case map[string]interface{}:
+		s.validateObject(pointer, typed, violations)
+	}
+}
+
+// compiledPattern returns the pattern compiled on parsing or compiles it if
+// the schema was built in Go code or Pattern was changed after parsing.
+func (s *Schema) compiledPattern() (*regexp.Regexp, error) {
+	if s.pattern != nil && s.pattern.String() == s.Pattern {
+		return s.pattern, nil
+	}
+	return regexp.Compile(s.Pattern)
+}
+
+func (s *Schema) validateNumber(val float64, report func(format string, args ...interface{})) {
+	if s.Minimum != nil && val < *s.Minimum {
+		report("value %v is less than minimum %v", val, *s.Minimum)
+	}
+	if s.Maximum != nil && val > *s.Maximum {
+		report("value %v is greater than maximum %v", val, *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && val <= *s.ExclusiveMinimum {
+		report("value %v is not greater than exclusiveMinimum %v", val, *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && val >= *s.ExclusiveMaximum {
+		report("value %v is not less than exclusiveMaximum %v", val, *s.ExclusiveMaximum)
+	}
+}
+
+func (s *Schema) validateObject(pointer string, props map[string]interface{}, violations *SchemaError) {
+	for _, key := range s.Required {
+		if _, ok := props[key]; !ok {
+			*violations = append(*violations, SchemaViolation{
+				Pointer: pointer + "/" + escapeJSONPointer(key),
+				Message: "required property is missing",
+			})
+		}
+	}
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		propPointer := pointer + "/" + escapeJSONPointer(key)
+		if propSchema, ok := s.Properties[key]; ok {
+			propSchema.validate(propPointer, props[key], violations)
+		} else if s.AdditionalProperties != nil {
+			if s.AdditionalProperties.RejectAll {
+				*violations = append(*violations, SchemaViolation{
+					Pointer: propPointer,
+					Message: "additional property is not allowed",
+				})
+			} else {
+				s.AdditionalProperties.validate(propPointer, props[key], violations)
+			}
+		}
+	}
+}
+
+func matchesAnyType(val interface{}, types []string) bool {
+	for _, expected := range types {
+		actual := jsonType(val)
+		if actual == expected || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(val interface{}) string {
+	switch typed := val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", val)
+	}
+}
+
+func containsValue(values []interface{}, val interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, val) {
+			return true
+		}
+	}
+	return false
+}
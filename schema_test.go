@@ -0,0 +1,142 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfig_ValidateSchema(t *testing.T) {
+	assert := assertions.New(t)
+
+	schema, err := LoadSchema("./test_schema.yaml")
+	assert.Nil(err)
+
+	assert.Nil(NewConfig("./test_config.yaml", Yaml).ValidateSchema(schema))
+	assert.Nil(NewConfig("./test_config.json", Json, WithSchema(schema)).ValidateSchema(schema))
+}
+
+func TestConfig_ValidateSchemaViolations(t *testing.T) {
+	assert := assertions.New(t)
+
+	schema, err := ParseSchema([]byte(`{
+		"type": "object",
+		"required": ["missing", "root"],
+		"properties": {
+			"root": {
+				"properties": {
+					"family1": {
+						"properties": {
+							"key1": {"type": "string", "minLength": 7, "pattern": "^prod"},
+							"key2.subkey2": {"type": ["string", "null"]}
+						},
+						"additionalProperties": false
+					},
+					"family2": {"enum": ["test1"], "maxLength": 3},
+					"family3.key1": {"type": "integer"}
+				}
+			},
+			"subroot": {
+				"additionalProperties": {
+					"additionalProperties": {"type": "number", "maximum": 1000, "exclusiveMinimum": 211}
+				}
+			},
+			"simpleprop": {"minimum": 5}
+		}
+	}`))
+	assert.Nil(err)
+
+	err = NewConfig("./test_config.yaml", Yaml).ValidateSchema(schema)
+	assert.Equal(SchemaError{
+		{Pointer: "/missing", Message: "required property is missing"},
+		{Pointer: "/root/family1/key1", Message: "length 6 is less than minLength 7"},
+		{Pointer: "/root/family1/key1", Message: `value "test11" doesn't match pattern "^prod"`},
+		{Pointer: "/root/family1/key2.subkey1", Message: "additional property is not allowed"},
+		{Pointer: "/root/family1/key2.subkey2", Message: "expected type string or null, got integer"},
+		{Pointer: "/root/family2", Message: "value test2 is not one of [test1]"},
+		{Pointer: "/root/family2", Message: "length 5 is greater than maxLength 3"},
+		{Pointer: "/root/family3.key1", Message: "expected type integer, got boolean"},
+		{Pointer: "/simpleprop", Message: "value 3 is less than minimum 5"},
+		{Pointer: "/subroot/family1/key1", Message: "value 211 is not greater than exclusiveMinimum 211"},
+		{Pointer: "/subroot/family1/key2.subkey1", Message: "value 2121.2121 is greater than maximum 1000"},
+		{Pointer: "/subroot/family1/key3.secret", Message: "expected type number, got string"},
+	}, err)
+	assert.Contains(err.Error(), "/missing: required property is missing; /root/family1/key1: length 6")
+}
+
+func TestConfig_ValidateSchemaLists(t *testing.T) {
+	assert := assertions.New(t)
+
+	schema, err := ParseSchema([]byte(`
+properties:
+  servers:
+    type: array
+    maxItems: 2
+    items:
+      required: [host, tls]
+      properties:
+        port: {type: integer, maximum: 8081}
+  kafka: true
+`))
+	assert.Nil(err)
+
+	err = NewConfig("./test_config_lists.yaml", Yaml).ValidateSchema(schema)
+	assert.Equal(SchemaError{
+		{Pointer: "/servers", Message: "3 items is greater than maxItems 2"},
+		{Pointer: "/servers/1/tls", Message: "required property is missing"},
+		{Pointer: "/servers/2/tls", Message: "required property is missing"},
+		{Pointer: "/servers/2/port", Message: "value 8082 is greater than maximum 8081"},
+	}, err)
+}
+
+func TestParseSchema_Invalid(t *testing.T) {
+	assert := assertions.New(t)
+
+	_, err := ParseSchema([]byte(`{"type": 5}`))
+	assert.NotNil(err)
+	_, err = ParseSchema([]byte(`{"pattern": "("}`))
+	assert.NotNil(err)
+	_, err = ParseSchema([]byte(`[`))
+	assert.NotNil(err)
+	_, err = LoadSchema("./missing_schema.yaml")
+	assert.NotNil(err)
+}
+
+func TestConfig_WithSchema(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{"required": ["missing"]}`))
+	assertions.New(t).Nil(err)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on schema violation")
+		}
+	}()
+	_ = NewConfig("./test_config.yaml", Yaml, WithSchema(schema))
+}
+
+func TestConfig_ValidateSchemaBuiltInGo(t *testing.T) {
+	assert := assertions.New(t)
+
+	schema := &Schema{
+		Properties: map[string]*Schema{
+			"root": {
+				Properties: map[string]*Schema{
+					"family1": {
+						Properties: map[string]*Schema{
+							"key1": {Type: []string{"string"}, Pattern: "^prod"},
+						},
+						AdditionalProperties: &Schema{RejectAll: true},
+					},
+					"family2": {Pattern: "("},
+				},
+			},
+			"simpleprop": {Type: []string{"string"}},
+		},
+	}
+	err := NewConfig("./test_config.yaml", Yaml).ValidateSchema(schema)
+	assert.Equal(SchemaError{
+		{Pointer: "/root/family1/key1", Message: `value "test11" doesn't match pattern "^prod"`},
+		{Pointer: "/root/family1/key2.subkey1", Message: "additional property is not allowed"},
+		{Pointer: "/root/family1/key2.subkey2", Message: "additional property is not allowed"},
+		{Pointer: "/root/family2", Message: "invalid pattern \"(\": error parsing regexp: missing closing ): `(`"},
+		{Pointer: "/simpleprop", Message: "expected type string, got integer"},
+	}, err)
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetStringSlice returns list of strings read from property, e.g. the list
+// collected by wildcard key 'servers[*].host'. Scalar property is returned as
+// a single element list.
+//
+// If property for the specified key is missing, it will try to read value from
+// the environment variable, splitting it by commas, see GetString for the env
+// variable name. If both property and env variable are missing it will return
+// the provided defaultVal or nil in case there was no default specified.
+func (c *Config) GetStringSlice(key string, defaultVal ...[]string) []string {
+	elems, found := c.getSlice(key)
+	if !found {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return nil
+	}
+	result := make([]string, len(elems))
+	for i, elem := range elems {
+		result[i] = fmt.Sprintf("%v", elem)
+	}
+	return result
+}
+
+// GetIntSlice returns list of ints read from property, see GetStringSlice.
+// String elements are parsed, it panics if an element isn't convertible to int.
+func (c *Config) GetIntSlice(key string, defaultVal ...[]int) []int {
+	elems, found := c.getSlice(key)
+	if !found {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return nil
+	}
+	result := make([]int, len(elems))
+	for i, elem := range elems {
+		result[i] = propToInt(fmt.Sprintf("%s[%d]", key, i), elem)
+	}
+	return result
+}
+
+// GetFloat64Slice returns list of floats read from property, see GetStringSlice.
+// String elements are parsed, it panics if an element isn't convertible to float.
+func (c *Config) GetFloat64Slice(key string, defaultVal ...[]float64) []float64 {
+	elems, found := c.getSlice(key)
+	if !found {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return nil
+	}
+	result := make([]float64, len(elems))
+	for i, elem := range elems {
+		result[i] = propToFloat(fmt.Sprintf("%s[%d]", key, i), elem, 64)
+	}
+	return result
+}
+
+// GetBoolSlice returns list of bools read from property, see GetStringSlice.
+func (c *Config) GetBoolSlice(key string, defaultVal ...[]bool) []bool {
+	elems, found := c.getSlice(key)
+	if !found {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return nil
+	}
+	result := make([]bool, len(elems))
+	for i, elem := range elems {
+		result[i] = propToBool(fmt.Sprintf("%s[%d]", key, i), elem)
+	}
+	return result
+}
+
+// getSlice returns elements of the list property or of the comma-separated
+// env variable value. The last returned value is false if both are missing.
+func (c *Config) getSlice(key string) ([]interface{}, bool) {
+	prop := c.GetProp(key)
+	if prop == nil {
+		env := c.readStringFromEnv(key)
+		if env == "" {
+			return nil, false
+		}
+		var elems []interface{}
+		for _, elem := range strings.Split(env, ",") {
+			elems = append(elems, strings.TrimSpace(elem))
+		}
+		return elems, true
+	}
+	if list, ok := prop.([]interface{}); ok {
+		return list, true
+	}
+	return []interface{}{prop}, true
+}
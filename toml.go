@@ -0,0 +1,19 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlToJSON converts TOML document to JSON, so it's unmarshalled into the same
+// properties tree as YAML and JSON files. Tables and dotted keys become nested
+// sections, quoted keys containing dots are kept as literal dotted keys.
+// Dates and times are converted to strings in RFC 3339 format.
+func tomlToJSON(data []byte) ([]byte, error) {
+	var tomlMap map[string]interface{}
+	if err := toml.Unmarshal(data, &tomlMap); err != nil {
+		return nil, err
+	}
+	return json.Marshal(tomlMap)
+}
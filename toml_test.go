@@ -0,0 +1,32 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfig_Toml(t *testing.T) {
+	config := NewConfig("./test_config.toml", Toml)
+	testConfigPositiveCases(t, config)
+}
+
+func TestConfig_TomlTablesAndLists(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config.toml", Toml)
+
+	assert.Equal("beta.example.com", config.GetString("servers[-1].host"))
+	assert.Equal("2021-05-27T07:32:00Z", config.GetString("servers[0].started"))
+	assert.Equal([]string{"key1", "key2"}, config.Keys("root.family3"))
+	assert.Equal(2121.2121, config.GetFloat64("subroot.family1.key2.subkey1"))
+	assert.Equal("file ./test_config.toml", config.Explain("root.family2").Source.String())
+}
+
+func TestConfig_TomlParsingErr(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic on toml parsing error")
+		}
+	}()
+	_ = NewConfig("./test_config.yaml", Toml)
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions limits the number of similar known keys suggested for unknown key.
+const maxSuggestions = 3
+
+// UnknownKey describes property present in config file that was neither read
+// by the application nor declared as known.
+type UnknownKey struct {
+	// Key is the fully qualified dotted key of the property.
+	Key string
+	// Suggestions lists known keys similar to the unknown one, most similar first.
+	Suggestions []string
+}
+
+// UnknownKeysError is returned from CheckUnknownKeys in strict mode.
+type UnknownKeysError []UnknownKey
+
+// WithStrictKeys enables strict mode: CheckUnknownKeys returns error if config
+// file contains unknown keys instead of only logging warnings.
+func WithStrictKeys() Option {
+	return func(c *Config) {
+		c.strictKeys = true
+	}
+}
+
+// DeclareKnownKeys declares properties that may be present in config file even
+// if the application doesn't read them. Declaring a section makes all its nested
+// properties known. Keys declared with Expect are known as well.
+func (c *Config) DeclareKnownKeys(keys ...string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.declared == nil {
+		c.declared = make(map[string]bool)
+	}
+	for _, key := range keys {
+		c.declared[c.normalizeKey(key)] = true
+	}
+	// declared keys may take values of separate arguments, see WithArgs
+	c.argsParsed = nil
+	return c
+}
+
+// UnknownKeys returns properties of config file that were never requested by
+// getters or IsSet and were not declared with DeclareKnownKeys or Expect. It is meant to be called
+// after the application has read or declared all properties it uses, e.g. to
+// catch typos like 'databse.host'. Each unknown key comes with suggestions of
+// known keys within small edit distance.
+func (c *Config) UnknownKeys() []UnknownKey {
+	known := c.knownKeys()
+	var unknown []UnknownKey
+	for _, key := range c.AllKeys() {
+		if isKnownKey(key, known) {
+			continue
+		}
+		unknown = append(unknown, UnknownKey{Key: key, Suggestions: suggestKeys(key, known)})
+	}
+	return unknown
+}
+
+// CheckUnknownKeys logs a warning for every unknown key, see UnknownKeys.
+// In strict mode it also returns UnknownKeysError listing all of them.
+func (c *Config) CheckUnknownKeys() error {
+	unknown := c.UnknownKeys()
+	for _, key := range unknown {
+		c.logger.Printf("Warning: %s", key)
+	}
+	if c.strictKeys && len(unknown) > 0 {
+		return UnknownKeysError(unknown)
+	}
+	return nil
+}
+
+func (e UnknownKeysError) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, key := range e {
+		messages = append(messages, key.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// String formats the unknown key with suggestions in human-readable form.
+func (u UnknownKey) String() string {
+	msg := fmt.Sprintf("unknown property %s", u.Key)
+	if len(u.Suggestions) > 0 {
+		msg += fmt.Sprintf(", did you mean %s?", strings.Join(u.Suggestions, " or "))
+	}
+	return msg
+}
+
+// markConsumed remembers the key requested by the application, whether it is
+// present in config file or not, so it is known and can be suggested for typos.
+func (c *Config) markConsumed(key string) {
+	c.consumed.Store(key, true)
+}
+
+func (c *Config) knownKeys() []string {
+	unique := make(map[string]bool)
+	c.consumed.Range(func(key, _ interface{}) bool {
+		unique[key.(string)] = true
+		return true
+	})
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for key := range c.declared {
+		unique[key] = true
+	}
+	for _, exp := range c.expected {
+		unique[c.normalizeKey(exp.key)] = true
+	}
+	known := make([]string, 0, len(unique))
+	for key := range unique {
+		known = append(known, key)
+	}
+	sort.Strings(known)
+	return known
+}
+
+// isKnownKey reports whether the property of config file is covered by one of
+// the known keys: the key itself, its section or an element of the list it holds.
+func isKnownKey(key string, known []string) bool {
+	for _, knownKey := range known {
+		if knownKey == key ||
+			strings.HasPrefix(key, knownKey+".") ||
+			strings.HasPrefix(knownKey, key+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestKeys returns known keys within edit distance of one third of the key length.
+// Known sections are compared with the same number of leading segments of the key,
+// e.g. for unknown key 'databse.user' and known section 'database' the suggestion
+// is 'database.user'.
+func suggestKeys(key string, known []string) []string {
+	distances := make(map[string]int)
+	var suggestions []string
+	segments := strings.Split(key, ".")
+	for _, knownKey := range known {
+		compared, suggestion := key, knownKey
+		if knownSegments := strings.Count(knownKey, ".") + 1; knownSegments < len(segments) {
+			compared = strings.Join(segments[:knownSegments], ".")
+			suggestion = knownKey + "." + strings.Join(segments[knownSegments:], ".")
+		}
+		maxDistance := len(compared) / 3
+		if maxDistance < 1 {
+			maxDistance = 1
+		}
+		distance := editDistance(compared, knownKey)
+		if _, seen := distances[suggestion]; seen || distance > maxDistance {
+			continue
+		}
+		distances[suggestion] = distance
+		suggestions = append(suggestions, suggestion)
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return distances[suggestions[i]] < distances[suggestions[j]]
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
+
+// editDistance returns Levenshtein distance between the strings.
+func editDistance(a, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	prev := make([]int, len(runesB)+1)
+	curr := make([]int, len(runesB)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(runesA); i++ {
+		curr[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(runesB)]
+}
+
+func minInt(first int, others ...int) int {
+	result := first
+	for _, val := range others {
+		if val < result {
+			result = val
+		}
+	}
+	return result
+}
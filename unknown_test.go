@@ -0,0 +1,82 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfig_UnknownKeys(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_unknown.yaml", Yaml)
+	assert.Len(config.UnknownKeys(), 7)
+
+	config.GetString("database.host")
+	config.GetInt("database.port")
+	config.GetInt("timeout", 10)
+	config.GetString("servers[0].host")
+	config.Expect("database.user", TypeString)
+	config.DeclareKnownKeys("logging")
+
+	assert.Equal([]UnknownKey{
+		{Key: "databse.user", Suggestions: []string{"database.user"}},
+		{Key: "timeuot", Suggestions: []string{"timeout"}},
+	}, config.UnknownKeys())
+}
+
+func TestConfig_UnknownKeysMissingRequested(t *testing.T) {
+	assert := assertions.New(t)
+
+	path := writeTempFile(t, "config.yaml", "databse:\n  host: localhost\n")
+	config := NewConfig(path, Yaml)
+	assert.Equal("default", config.GetString("database.host", "default"))
+	assert.False(config.IsSet("database.port"))
+
+	assert.Equal([]UnknownKey{
+		{Key: "databse.host", Suggestions: []string{"database.host", "database.port"}},
+	}, config.UnknownKeys())
+}
+
+func TestConfig_CheckUnknownKeys(t *testing.T) {
+	assert := assertions.New(t)
+
+	logger := &testLogger{}
+	config := NewConfig("./test_config_unknown.yaml", Yaml, WithLogger(logger))
+	config.DeclareKnownKeys("database", "logging", "servers", "timeout")
+
+	assert.Nil(config.CheckUnknownKeys())
+	assert.Equal([]string{
+		"Warning: unknown property databse.user, did you mean database.user?",
+		"Warning: unknown property timeuot, did you mean timeout?",
+	}, logger.messages)
+}
+
+func TestConfig_CheckUnknownKeysStrict(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_unknown.yaml", Yaml, WithStrictKeys(), WithLogger(&testLogger{}))
+	config.DeclareKnownKeys("database.host", "database.port", "logging.level", "logging.format", "servers", "databse")
+	assert.True(config.IsSet("timeuot"))
+
+	assert.Nil(config.CheckUnknownKeys())
+
+	config = NewConfig("./test_config_unknown.yaml", Yaml, WithStrictKeys(), WithLogger(&testLogger{}))
+	config.DeclareKnownKeys("database", "logging", "servers", "timeout")
+	err := config.CheckUnknownKeys()
+	assert.Equal(UnknownKeysError{
+		{Key: "databse.user", Suggestions: []string{"database.user"}},
+		{Key: "timeuot", Suggestions: []string{"timeout"}},
+	}, err)
+	assert.Equal("unknown property databse.user, did you mean database.user?; "+
+		"unknown property timeuot, did you mean timeout?", err.Error())
+}
+
+func TestEditDistance(t *testing.T) {
+	assert := assertions.New(t)
+
+	assert.Equal(0, editDistance("database", "database"))
+	assert.Equal(1, editDistance("databse", "database"))
+	assert.Equal(2, editDistance("timeuot", "timeout"))
+	assert.Equal(3, editDistance("kitten", "sitting"))
+	assert.Equal(4, editDistance("", "host"))
+}
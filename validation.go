@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hostnameRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// Validator is implemented by bound structs requiring custom checks, e.g. checks
+// involving several fields. Validate is called by Bind after the struct is bound
+// and its fields are checked against the rules of the 'validate' tag.
+//
+// Rules of the 'validate' tag are separated by commas, e.g. `validate:"nonzero,max=10"`:
+//   - nonzero: value is not the zero value of its type;
+//   - min=N, max=N: number is within the limit, length of string or slice is within the limit;
+//   - oneof=a b c: value is one of the space-separated options;
+//   - regex=EXPR: string matches the regular expression, must be the last rule
+//     since the expression may contain commas;
+//   - url: string is an absolute URL with scheme and host;
+//   - hostname: string is a valid hostname according to RFC 1123;
+//   - port: number or numeric string is within the range 1-65535.
+type Validator interface {
+	Validate() error
+}
+
+type validationRule struct {
+	name string
+	arg  string
+}
+
+func validateField(key string, field reflect.StructField, fieldVal reflect.Value, errs *ValidationError) {
+	tag, ok := field.Tag.Lookup("validate")
+	if !ok || tag == "" {
+		return
+	}
+	for _, rule := range parseValidationRules(tag) {
+		if msg := checkRule(rule, fieldVal); msg != "" {
+			*errs = append(*errs, KeyError{Key: key, EnvVar: envVarName(key), Value: fieldVal.Interface(), Message: msg})
+		}
+	}
+}
+
+func validateStruct(key string, structVal reflect.Value, errs *ValidationError) {
+	if !structVal.CanAddr() {
+		return
+	}
+	validator, ok := structVal.Addr().Interface().(Validator)
+	if !ok {
+		return
+	}
+	if err := validator.Validate(); err != nil {
+		*errs = append(*errs, KeyError{Key: key, EnvVar: envVarName(key), Message: err.Error()})
+	}
+}
+
+func parseValidationRules(tag string) []validationRule {
+	var rules []validationRule
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else if commaIdx := strings.Index(tag, ","); commaIdx != -1 {
+			part, tag = tag[:commaIdx], tag[commaIdx+1:]
+		} else {
+			part, tag = tag, ""
+		}
+		rule := validationRule{name: strings.TrimSpace(part)}
+		if eqIdx := strings.Index(part, "="); eqIdx != -1 {
+			rule.name, rule.arg = strings.TrimSpace(part[:eqIdx]), part[eqIdx+1:]
+		}
+		if rule.name != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// checkRule returns description of the violation or empty string if the value matches the rule.
+func checkRule(rule validationRule, val reflect.Value) string {
+	switch rule.name {
+	case "nonzero":
+		if val.IsZero() {
+			return "value must not be zero"
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(rule.arg, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid %s rule argument %q", rule.name, rule.arg)
+		}
+		measure, what := measureValue(val)
+		if rule.name == "min" && measure < limit {
+			return fmt.Sprintf("%s must be at least %v", what, limit)
+		}
+		if rule.name == "max" && measure > limit {
+			return fmt.Sprintf("%s must be at most %v", what, limit)
+		}
+	case "oneof":
+		options := strings.Fields(rule.arg)
+		strVal := fmt.Sprintf("%v", val.Interface())
+		for _, option := range options {
+			if option == strVal {
+				return ""
+			}
+		}
+		return fmt.Sprintf("value must be one of [%s]", strings.Join(options, " "))
+	case "regex":
+		expr, err := regexp.Compile(rule.arg)
+		if err != nil {
+			return fmt.Sprintf("invalid regex rule argument %q", rule.arg)
+		}
+		if !expr.MatchString(fmt.Sprintf("%v", val.Interface())) {
+			return fmt.Sprintf("value must match regex %q", rule.arg)
+		}
+	case "url":
+		parsed, err := url.Parse(fmt.Sprintf("%v", val.Interface()))
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return "value must be an absolute URL"
+		}
+	case "hostname":
+		hostname := fmt.Sprintf("%v", val.Interface())
+		if len(hostname) > 253 || !hostnameRegexp.MatchString(hostname) {
+			return "value must be a valid hostname"
+		}
+	case "port":
+		port, err := strconv.Atoi(fmt.Sprintf("%v", val.Interface()))
+		if err != nil || port < 1 || port > 65535 {
+			return "value must be a port number between 1 and 65535"
+		}
+	default:
+		return fmt.Sprintf("unknown validation rule %q", rule.name)
+	}
+	return ""
+}
+
+// measureValue returns the number compared by min and max rules and its description.
+func measureValue(val reflect.Value) (float64, string) {
+	switch val.Kind() {
+	case reflect.String:
+		return float64(len([]rune(val.String()))), "length"
+	case reflect.Slice:
+		return float64(val.Len()), "length"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), "value"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), "value"
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), "value"
+	default:
+		return 0, "value"
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	assertions "github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type testValidatedServer struct {
+	Host string `validate:"hostname"`
+	Port int    `validate:"port"`
+}
+
+type testValidatedConfig struct {
+	Name     string   `validate:"nonzero"`
+	Level    string   `validate:"oneof=debug info warn"`
+	Workers  int      `validate:"min=1,max=8"`
+	Ratio    float64  `validate:"max=1"`
+	Endpoint string   `validate:"url"`
+	Host     string   `validate:"hostname"`
+	Port     int      `validate:"port"`
+	Code     string   `validate:"regex=^[A-Z]{3}-[0-9]{2}$"`
+	Tags     []string `validate:"max=2"`
+	Servers  []testValidatedServer
+	MinLen   string `config:"name" validate:"min=3"`
+	Unknown  string `config:"level" validate:"unique"`
+}
+
+type testCrossFieldConfig struct {
+	Workers int
+	Port    int
+}
+
+func (c *testCrossFieldConfig) Validate() error {
+	if c.Workers > c.Port {
+		return errors.New("workers must not exceed port")
+	}
+	return nil
+}
+
+func TestConfig_BindValidation(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_bind.yaml", Yaml)
+
+	var valid struct {
+		Name    string   `validate:"nonzero,min=4,max=4"`
+		Workers int      `validate:"min=1,max=8"`
+		Ratio   float32  `validate:"min=0,max=1"`
+		Tags    []string `validate:"min=1"`
+		Db      testValidatedServer
+		Servers []testValidatedServer
+	}
+	assert.Nil(config.Bind("app", &valid))
+
+	invalid := testValidatedConfig{}
+	err := config.Bind("invalid", &invalid)
+	assert.Equal(ValidationError{
+		{Key: "invalid.name", EnvVar: "INVALID_NAME", Value: "", Message: "value must not be zero"},
+		{Key: "invalid.level", EnvVar: "INVALID_LEVEL", Value: "verbose", Message: "value must be one of [debug info warn]"},
+		{Key: "invalid.workers", EnvVar: "INVALID_WORKERS", Value: 0, Message: "value must be at least 1"},
+		{Key: "invalid.ratio", EnvVar: "INVALID_RATIO", Value: 1.5, Message: "value must be at most 1"},
+		{Key: "invalid.endpoint", EnvVar: "INVALID_ENDPOINT", Value: "not a url", Message: "value must be an absolute URL"},
+		{Key: "invalid.host", EnvVar: "INVALID_HOST", Value: "-bad-host-", Message: "value must be a valid hostname"},
+		{Key: "invalid.port", EnvVar: "INVALID_PORT", Value: 70000, Message: "value must be a port number between 1 and 65535"},
+		{Key: "invalid.code", EnvVar: "INVALID_CODE", Value: "AB-12", Message: `value must match regex "^[A-Z]{3}-[0-9]{2}$"`},
+		{Key: "invalid.tags", EnvVar: "INVALID_TAGS", Value: []string{"a", "b", "c"}, Message: "length must be at most 2"},
+		{Key: "invalid.name", EnvVar: "INVALID_NAME", Value: "", Message: "length must be at least 3"},
+		{Key: "invalid.level", EnvVar: "INVALID_LEVEL", Value: "verbose", Message: `unknown validation rule "unique"`},
+	}, err)
+	assert.Contains(err.Error(), "invalid.port (env INVALID_PORT): value must be a port number between 1 and 65535, got 70000")
+}
+
+func TestConfig_BindValidator(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig("./test_config_bind.yaml", Yaml)
+
+	var holder struct {
+		Db testCrossFieldConfig
+	}
+	assert.Nil(config.Bind("app", &holder))
+
+	server := testCrossFieldConfig{}
+	assert.Nil(config.Bind("app.servers[0]", &server))
+
+	invalid := testCrossFieldConfig{}
+	err := config.Bind("app", &invalid)
+	assert.Equal(ValidationError{
+		{Key: "app", EnvVar: "APP", Message: "workers must not exceed port"},
+	}, err)
+}
+
+func TestParseValidationRules(t *testing.T) {
+	assert := assertions.New(t)
+
+	assert.Equal([]validationRule{
+		{name: "nonzero"},
+		{name: "min", arg: "1"},
+		{name: "oneof", arg: "a b"},
+		{name: "regex", arg: "^a{1,2}$"},
+	}, parseValidationRules("nonzero, min=1,oneof=a b,regex=^a{1,2}$"))
+	assert.Equal([]validationRule{{name: "min", arg: "x"}}, parseValidationRules("min=x"))
+	assert.Equal("invalid min rule argument \"x\"", checkRule(validationRule{name: "min", arg: "x"}, reflect.ValueOf(1)))
+	assert.Equal("invalid regex rule argument \"(\"", checkRule(validationRule{name: "regex", arg: "("}, reflect.ValueOf("a")))
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// yaml11Bools holds plain scalars YAML 1.1 resolves to booleans. They are kept
+// booleans for compatibility with documents written for YAML 1.1 parsers.
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"on": true, "On": true, "ON": true,
+	"n": false, "N": false, "no": false, "No": false, "NO": false,
+	"off": false, "Off": false, "OFF": false,
+}
+
+// parseYamlNode parses YAML or JSON document into the node tree, which both
+// properties and their line numbers are read from. Empty document gives nil node.
+// The 'yaml: ' prefix of parser errors is dropped, callers prefix errors with
+// the format name.
+func parseYamlNode(data []byte) (*yamlv3.Node, error) {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &root); err != nil {
+		return nil, errors.New(strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	return root.Content[0], nil
+}
+
+// yamlToJSON converts YAML document to JSON, see yamlNodeToProps.
+func yamlToJSON(data []byte) ([]byte, error) {
+	root, err := parseYamlNode(data)
+	if err != nil {
+		return nil, err
+	}
+	props, err := yamlNodeToProps(root)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(props)
+}
+
+// yamlNodeToProps converts the root node of the document into the properties
+// tree. Document that is not a mapping is reported as error.
+func yamlNodeToProps(root *yamlv3.Node) (map[string]interface{}, error) {
+	if root == nil {
+		return make(map[string]interface{}), nil
+	}
+	val, err := yamlNodeToValue(root)
+	if err != nil {
+		return nil, err
+	}
+	if val == nil {
+		return make(map[string]interface{}), nil
+	}
+	props, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("line %d: document root is not a mapping", root.Line)
+	}
+	return props, nil
+}
+
+// yamlNodeToValue converts the node into map[string]interface{}, []interface{}
+// or scalar value. Scalar keys are converted to strings, other keys are
+// reported as error, and merge keys '<<' are resolved, with explicit keys of
+// the mapping taking precedence.
+func yamlNodeToValue(node *yamlv3.Node) (interface{}, error) {
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlNodeToValue(node.Content[0])
+	case yamlv3.AliasNode:
+		return yamlNodeToValue(node.Alias)
+	case yamlv3.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, elem := range node.Content {
+			val, err := yamlNodeToValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, val)
+		}
+		return list, nil
+	case yamlv3.MappingNode:
+		return yamlMappingToMap(node)
+	default:
+		return yamlScalarToValue(node)
+	}
+}
+
+func yamlMappingToMap(node *yamlv3.Node) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	explicit := make(map[string]interface{})
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valNode := node.Content[i], node.Content[i+1]
+		val, err := yamlNodeToValue(valNode)
+		if err != nil {
+			return nil, err
+		}
+		if keyNode.Kind == yamlv3.ScalarNode && keyNode.ShortTag() == "!!merge" {
+			if err := mergeYamlValue(result, val, keyNode.Line); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		key, err := yamlMapKey(keyNode)
+		if err != nil {
+			return nil, err
+		}
+		explicit[key] = val
+	}
+	for key, val := range explicit {
+		result[key] = val
+	}
+	return result, nil
+}
+
+// yamlMapKey converts scalar key to string the way the values of the same type
+// are formatted in JSON. Null, list and mapping keys can't be converted.
+func yamlMapKey(node *yamlv3.Node) (string, error) {
+	if node.Kind == yamlv3.AliasNode {
+		return yamlMapKey(node.Alias)
+	}
+	if node.Kind == yamlv3.ScalarNode {
+		val, err := yamlScalarToValue(node)
+		if err != nil {
+			return "", err
+		}
+		switch typed := val.(type) {
+		case string:
+			return typed, nil
+		case bool:
+			return strconv.FormatBool(typed), nil
+		case int:
+			return strconv.Itoa(typed), nil
+		case int64:
+			return strconv.FormatInt(typed, 10), nil
+		case uint64:
+			return strconv.FormatUint(typed, 10), nil
+		case float64:
+			return strconv.FormatFloat(typed, 'g', -1, 64), nil
+		}
+	}
+	return "", fmt.Errorf("line %d: unsupported map key %q of type %s", node.Line, node.Value, node.ShortTag())
+}
+
+// mergeYamlValue adds the entries of the mapping or the list of mappings merged
+// with '<<' key. Entries of earlier mappings in the list take precedence.
+func mergeYamlValue(result map[string]interface{}, val interface{}, line int) error {
+	switch typed := val.(type) {
+	case map[string]interface{}:
+		for key, entry := range typed {
+			if _, ok := result[key]; !ok {
+				result[key] = entry
+			}
+		}
+	case []interface{}:
+		for _, elem := range typed {
+			if _, ok := elem.(map[string]interface{}); !ok {
+				return fmt.Errorf("line %d: merged value is not a mapping", line)
+			}
+			if err := mergeYamlValue(result, elem, line); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("line %d: merged value is not a mapping", line)
+	}
+	return nil
+}
+
+func yamlScalarToValue(node *yamlv3.Node) (interface{}, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool", "!!int", "!!float":
+		var val interface{}
+		if err := node.Decode(&val); err != nil {
+			return nil, err
+		}
+		return val, nil
+	case "!!binary":
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(node.Value), ""))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid binary value: %v", node.Line, err)
+		}
+		return string(decoded), nil
+	case "!!str":
+		if node.Style == 0 {
+			if val, ok := yaml11Bools[node.Value]; ok {
+				return val, nil
+			}
+		}
+		return node.Value, nil
+	default:
+		return node.Value, nil
+	}
+}
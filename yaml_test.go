@@ -0,0 +1,78 @@
+package config
+
+import (
+	assertions "github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfig_YamlAnchorsAndBools(t *testing.T) {
+	assert := assertions.New(t)
+
+	path := writeTempFile(t, "config.yaml", `defaults: &defaults
+  timeout: 30
+  retries: 3
+service:
+  <<: *defaults
+  retries: 5
+  enabled: yes
+  quoted: "yes"
+  mode: off
+  started: 2021-05-27
+  hosts: [a, b]
+`)
+	config := NewConfig(path, Yaml)
+
+	assert.Equal(30, config.GetInt("service.timeout"))
+	assert.Equal(5, config.GetInt("service.retries"))
+	assert.Equal(true, config.GetProp("service.enabled"))
+	assert.Equal("yes", config.GetProp("service.quoted"))
+	assert.Equal(false, config.GetProp("service.mode"))
+	assert.Equal("2021-05-27", config.GetProp("service.started"))
+	assert.Equal([]string{"a", "b"}, config.GetStringSlice("service.hosts"))
+	assert.Equal(6, config.Explain("service.retries").Source.Line)
+	assert.Equal(11, config.Explain("service.hosts[1]").Source.Line)
+}
+
+func TestParseYamlNode_Errors(t *testing.T) {
+	assert := assertions.New(t)
+
+	_, err := yamlToJSON([]byte("- a\n- b\n"))
+	assert.EqualError(err, "line 1: document root is not a mapping")
+	_, err = yamlToJSON([]byte("a: {<<: [1]}\n"))
+	assert.EqualError(err, "line 1: merged value is not a mapping")
+	plane, err := yamlToJSON([]byte(""))
+	assert.NoError(err)
+	assert.Equal("{}", string(plane))
+}
+
+func TestConfig_JsonEscapes(t *testing.T) {
+	assert := assertions.New(t)
+
+	path := writeTempFile(t, "config.json", `{
+  "url": "http:\/\/example.com",
+  "smile": "\ud83d\ude00",
+  "db": {"host": "localhost"}
+}`)
+	config := NewConfig(path, Json)
+	assert.Equal("http://example.com", config.GetString("url"))
+	assert.Equal("\U0001F600", config.GetString("smile"))
+	// line numbers are unknown for JSON the yaml parser can't read
+	assert.Equal(0, config.Explain("db.host").Source.Line)
+
+	config = NewConfig(writeTempFile(t, "lines.json", "{\n  \"db\": {\n    \"host\": \"localhost\"\n  }\n}"), Json)
+	assert.Equal(3, config.Explain("db.host").Source.Line)
+}
+
+func TestConfig_YamlKeysAndBinary(t *testing.T) {
+	assert := assertions.New(t)
+
+	config := NewConfig(writeTempFile(t, "config.yaml", "data: !!binary aGVsbG8=\n1: one\nyes: bool\n"), Yaml)
+	assert.Equal("hello", config.GetString("data"))
+	assert.Equal("one", config.GetString("1"))
+	assert.Equal("bool", config.GetString("true"))
+
+	_, err := yamlToJSON([]byte("? [1, 2]\n: complex\n"))
+	assert.EqualError(err, `line 1: unsupported map key "" of type !!seq`)
+	_, _, err = parseIndexed(Yaml, []byte("a: \"\\q\"\n"))
+	assert.EqualError(err, "yaml: found unknown escape character")
+}